@@ -4,14 +4,24 @@ package config
 import (
 	"flag"
 	"fmt"
+	"math"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // AppConfig holds all application configuration settings.
 type AppConfig struct {
-	// Database settings
-	DBPath string
+	// Database settings; the DSN is the database file path when using SQLite
+	DBPath   string
+	DBDriver string
+	DBDSN    string
+
+	// Database connection pool settings; zero keeps the database/sql default
+	DBMaxOpenConns int
+	DBMaxIdleConns int
 
 	// Server settings
 	Port int
@@ -19,14 +29,75 @@ type AppConfig struct {
 	// Timezone settings
 	Timezone string
 	Location *time.Location
+
+	// Tag settings; tags may use any color unless restricted to the palette
+	TagPalette       string
+	TagPaletteOnly   bool
+	TagColorStrategy string
+
+	// Frequency settings; a zero minimum interval allows any schedule, and cron
+	// expressions only take a seconds field when enabled
+	MinFrequencyInterval time.Duration
+	CronSeconds          bool
+
+	// Task settings; a zero default priority leaves new tasks without a priority
+	PriorityLevels  int
+	DefaultPriority int
+	OverdueGrace    time.Duration
+
+	// Priority escalation settings; a zero age disables escalation, otherwise it applies
+	// to tasks with an auto-escalating tag, or to every task when enabled globally
+	PriorityEscalationAge time.Duration
+	EscalateAllTasks      bool
+
+	// Trash settings; a zero retention keeps deleted records forever
+	TrashRetention time.Duration
+
+	// Notification settings; task resets are posted to the webhook URL when set
+	WebhookURL string
+
+	// WebSocket settings; a zero idle timeout keeps idle clients connected
+	WSIdleTimeout time.Duration
+	WSMaxClients  int
+
+	// Security settings; admin endpoints are only served when API keys are configured,
+	// and any origin may make cross-origin requests unless origins are configured
+	APIKeys     []string
+	CORSOrigins []string
+
+	// Rate limit settings; a zero rate disables rate limiting
+	RateLimit float64
+	RateBurst int
 }
 
 // ParseFlags parses command line flags and environment variables to create application configuration.
 // It follows the precedence: CLI flags > Environment variables > Default values.
 func ParseFlags() (*AppConfig, error) {
 	dbPath := flag.String("db-path", "", "Path to database file")
+	dbDriver := flag.String("db-driver", "", "Database driver (sqlite, postgres)")
+	dbDSN := flag.String("db-dsn", "", "Database connection string, required for postgres (default: the database file path)")
+	dbMaxOpenConns := flag.Int("db-max-open-conns", 0, "Maximum open database connections (0 = unlimited)")
+	dbMaxIdleConns := flag.Int("db-max-idle-conns", 0, "Maximum idle database connections (default 2)")
 	apiPort := flag.Int("port", 8080, "The port to listen to")
 	dbTimezone := flag.String("tz", "", "Timezone for scheduler (e.g., America/Denver, UTC)")
+	overdueGrace := flag.Duration("overdue-grace", 0, "How long past its due date a task becomes overdue (e.g., 15m)")
+	defaultPriority := flag.Int("default-priority", 0, "Priority given to new tasks created without one (0 = none)")
+	priorityLevels := flag.Int("priority-levels", 0, "Number of task priority levels (default 5)")
+	escalationAge := flag.Duration("priority-escalation-age", 0, "Raise the priority of tasks left incomplete longer than this once a day (e.g., 72h, 0 = disabled)")
+	escalateAll := flag.Bool("escalate-all-tasks", false, "Escalate every task rather than only tasks with an auto-escalating tag")
+	minFrequencyInterval := flag.Duration("min-frequency-interval", 0, "Reject frequencies firing more often than this (e.g., 5m, 0 = disabled)")
+	cronSeconds := flag.Bool("cron-seconds", false, "Accept an optional leading seconds field in frequency cron expressions")
+	tagPalette := flag.String("tag-palette", "", "Palette for generated tag colors (default, colorblind)")
+	tagColorStrategy := flag.String("tag-color-strategy", "", "How tag colors are generated without a fixed palette (random, readable)")
+	tagPaletteOnly := flag.Bool("tag-palette-only", false, "Reject tag colors outside the tag palette (requires a palette other than default)")
+	trashRetention := flag.Duration("trash-retention", 0, "How long deleted records are kept before being purged (e.g., 720h, 0 = never)")
+	apiKeys := flag.String("api-keys", "", "Comma separated API keys accepted by protected endpoints")
+	corsOrigins := flag.String("cors-origins", "", "Comma separated origins allowed to make cross-origin requests (default: *)")
+	rateLimit := flag.Float64("rate-limit", 0, "Average API requests per second allowed per client IP (0 = unlimited)")
+	rateBurst := flag.Int("rate-burst", 0, "API requests a client may burst above the rate limit (default: the rate limit, at least 1)")
+	wsMaxClients := flag.Int("max-ws-clients", 0, "Maximum concurrent WebSocket clients (0 = unlimited)")
+	webhookURL := flag.String("webhook-url", "", "URL that receives a POST whenever a recurring task is reset")
+	wsIdleTimeout := flag.Duration("ws-idle-timeout", 0, "Disconnect WebSocket clients that send no messages for this long (e.g., 30m, 0 = never)")
 
 	flag.Parse()
 
@@ -41,6 +112,60 @@ func ParseFlags() (*AppConfig, error) {
 		config.DBPath = "dailies.db"
 	}
 
+	// Resolve database driver and DSN: CLI flag > env var > default
+	if *dbDriver != "" {
+		config.DBDriver = *dbDriver
+	} else if envDriver := os.Getenv("DB_DRIVER"); envDriver != "" {
+		config.DBDriver = envDriver
+	} else {
+		config.DBDriver = DriverSQLite
+	}
+	if *dbDSN != "" {
+		config.DBDSN = *dbDSN
+	} else {
+		config.DBDSN = os.Getenv("DB_DSN")
+	}
+	switch config.DBDriver {
+	case DriverSQLite:
+		if config.DBDSN == "" {
+			config.DBDSN = config.DBPath
+		}
+	case DriverPostgres:
+		if config.DBDSN == "" {
+			return nil, fmt.Errorf("a database DSN is required for the postgres driver")
+		}
+	default:
+		return nil, fmt.Errorf("invalid database driver '%s': must be sqlite or postgres", config.DBDriver)
+	}
+
+	// Resolve maximum open connections: CLI flag > env var > default
+	if *dbMaxOpenConns != 0 {
+		config.DBMaxOpenConns = *dbMaxOpenConns
+	} else if envOpen := os.Getenv("DB_MAX_OPEN_CONNS"); envOpen != "" {
+		open, err := strconv.Atoi(envOpen)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum open connections '%s': %w", envOpen, err)
+		}
+		config.DBMaxOpenConns = open
+	}
+	if config.DBMaxOpenConns < 0 {
+		return nil, fmt.Errorf("maximum open connections must not be negative")
+	}
+
+	// Resolve maximum idle connections: CLI flag > env var > default
+	if *dbMaxIdleConns != 0 {
+		config.DBMaxIdleConns = *dbMaxIdleConns
+	} else if envIdle := os.Getenv("DB_MAX_IDLE_CONNS"); envIdle != "" {
+		idle, err := strconv.Atoi(envIdle)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum idle connections '%s': %w", envIdle, err)
+		}
+		config.DBMaxIdleConns = idle
+	}
+	if config.DBMaxIdleConns < 0 {
+		return nil, fmt.Errorf("maximum idle connections must not be negative")
+	}
+
 	// Resolve port
 	config.Port = *apiPort
 
@@ -60,6 +185,236 @@ func ParseFlags() (*AppConfig, error) {
 	}
 	config.Location = location
 
+	// Resolve tag palette: CLI flag > env var > default
+	if *tagPalette != "" {
+		config.TagPalette = *tagPalette
+	} else if envPalette := os.Getenv("TAG_PALETTE"); envPalette != "" {
+		config.TagPalette = envPalette
+	} else {
+		config.TagPalette = "default"
+	}
+
+	// Resolve tag color strategy: CLI flag > env var > default
+	if *tagColorStrategy != "" {
+		config.TagColorStrategy = *tagColorStrategy
+	} else if envStrategy := os.Getenv("TAG_COLOR_STRATEGY"); envStrategy != "" {
+		config.TagColorStrategy = envStrategy
+	} else {
+		config.TagColorStrategy = "random"
+	}
+
+	// Resolve tag palette restriction: CLI flag > env var > default
+	if *tagPaletteOnly {
+		config.TagPaletteOnly = true
+	} else if envOnly := os.Getenv("TAG_PALETTE_ONLY"); envOnly != "" {
+		only, err := strconv.ParseBool(envOnly)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag palette only setting '%s': %w", envOnly, err)
+		}
+		config.TagPaletteOnly = only
+	}
+
+	// Resolve minimum frequency interval: CLI flag > env var > default
+	if *minFrequencyInterval != 0 {
+		config.MinFrequencyInterval = *minFrequencyInterval
+	} else if envInterval := os.Getenv("MIN_FREQUENCY_INTERVAL"); envInterval != "" {
+		interval, err := time.ParseDuration(envInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minimum frequency interval '%s': %w", envInterval, err)
+		}
+		config.MinFrequencyInterval = interval
+	}
+	if config.MinFrequencyInterval < 0 {
+		return nil, fmt.Errorf("minimum frequency interval must not be negative")
+	}
+
+	// Resolve cron seconds: CLI flag > env var > default
+	if *cronSeconds {
+		config.CronSeconds = true
+	} else if envSeconds := os.Getenv("CRON_SECONDS"); envSeconds != "" {
+		seconds, err := strconv.ParseBool(envSeconds)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron seconds setting '%s': %w", envSeconds, err)
+		}
+		config.CronSeconds = seconds
+	}
+
+	// Resolve priority levels: CLI flag > env var > default
+	if *priorityLevels != 0 {
+		config.PriorityLevels = *priorityLevels
+	} else if envLevels := os.Getenv("PRIORITY_LEVELS"); envLevels != "" {
+		levels, err := strconv.Atoi(envLevels)
+		if err != nil {
+			return nil, fmt.Errorf("invalid priority levels '%s': %w", envLevels, err)
+		}
+		config.PriorityLevels = levels
+	} else {
+		config.PriorityLevels = 5
+	}
+	if config.PriorityLevels < 2 {
+		return nil, fmt.Errorf("priority levels must be at least 2")
+	}
+
+	// Resolve default priority: CLI flag > env var > default
+	if *defaultPriority != 0 {
+		config.DefaultPriority = *defaultPriority
+	} else if envPriority := os.Getenv("DEFAULT_PRIORITY"); envPriority != "" {
+		priority, err := strconv.Atoi(envPriority)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default priority '%s': %w", envPriority, err)
+		}
+		config.DefaultPriority = priority
+	}
+	if config.DefaultPriority < 0 || config.DefaultPriority > config.PriorityLevels {
+		return nil, fmt.Errorf("default priority must be between 1 and %d", config.PriorityLevels)
+	}
+
+	// Resolve overdue grace period: CLI flag > env var > default
+	if *overdueGrace != 0 {
+		config.OverdueGrace = *overdueGrace
+	} else if envGrace := os.Getenv("OVERDUE_GRACE"); envGrace != "" {
+		grace, err := time.ParseDuration(envGrace)
+		if err != nil {
+			return nil, fmt.Errorf("invalid overdue grace '%s': %w", envGrace, err)
+		}
+		config.OverdueGrace = grace
+	}
+	if config.OverdueGrace < 0 {
+		return nil, fmt.Errorf("overdue grace must not be negative")
+	}
+
+	// Resolve priority escalation age: CLI flag > env var > default
+	if *escalationAge != 0 {
+		config.PriorityEscalationAge = *escalationAge
+	} else if envAge := os.Getenv("PRIORITY_ESCALATION_AGE"); envAge != "" {
+		age, err := time.ParseDuration(envAge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid priority escalation age '%s': %w", envAge, err)
+		}
+		config.PriorityEscalationAge = age
+	}
+	if config.PriorityEscalationAge < 0 {
+		return nil, fmt.Errorf("priority escalation age must not be negative")
+	}
+
+	// Resolve global escalation: CLI flag > env var > default
+	if *escalateAll {
+		config.EscalateAllTasks = true
+	} else if envAll := os.Getenv("ESCALATE_ALL_TASKS"); envAll != "" {
+		all, err := strconv.ParseBool(envAll)
+		if err != nil {
+			return nil, fmt.Errorf("invalid escalate all tasks setting '%s': %w", envAll, err)
+		}
+		config.EscalateAllTasks = all
+	}
+
+	// Resolve trash retention: CLI flag > env var > default
+	if *trashRetention != 0 {
+		config.TrashRetention = *trashRetention
+	} else if envRetention := os.Getenv("TRASH_RETENTION"); envRetention != "" {
+		retention, err := time.ParseDuration(envRetention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trash retention '%s': %w", envRetention, err)
+		}
+		config.TrashRetention = retention
+	}
+	if config.TrashRetention < 0 {
+		return nil, fmt.Errorf("trash retention must not be negative")
+	}
+
+	// Resolve webhook URL: CLI flag > env var > default
+	if *webhookURL != "" {
+		config.WebhookURL = *webhookURL
+	} else {
+		config.WebhookURL = os.Getenv("WEBHOOK_URL")
+	}
+	if config.WebhookURL != "" {
+		parsed, err := url.Parse(config.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid webhook URL '%s': must be an absolute http or https URL", config.WebhookURL)
+		}
+	}
+
+	// Resolve WebSocket idle timeout: CLI flag > env var > default
+	if *wsIdleTimeout != 0 {
+		config.WSIdleTimeout = *wsIdleTimeout
+	} else if envTimeout := os.Getenv("WS_IDLE_TIMEOUT"); envTimeout != "" {
+		timeout, err := time.ParseDuration(envTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WebSocket idle timeout '%s': %w", envTimeout, err)
+		}
+		config.WSIdleTimeout = timeout
+	}
+	if config.WSIdleTimeout < 0 {
+		return nil, fmt.Errorf("WebSocket idle timeout must not be negative")
+	}
+
+	// Resolve WebSocket client limit: CLI flag > env var > default
+	if *wsMaxClients != 0 {
+		config.WSMaxClients = *wsMaxClients
+	} else if envMax := os.Getenv("MAX_WS_CLIENTS"); envMax != "" {
+		max, err := strconv.Atoi(envMax)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WebSocket client limit '%s': %w", envMax, err)
+		}
+		config.WSMaxClients = max
+	}
+	if config.WSMaxClients < 0 {
+		return nil, fmt.Errorf("WebSocket client limit must not be negative")
+	}
+
+	// Resolve rate limit: CLI flag > env var > default
+	if *rateLimit != 0 {
+		config.RateLimit = *rateLimit
+	} else if envRate := os.Getenv("RATE_LIMIT"); envRate != "" {
+		rate, err := strconv.ParseFloat(envRate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit '%s': %w", envRate, err)
+		}
+		config.RateLimit = rate
+	}
+	if config.RateLimit < 0 {
+		return nil, fmt.Errorf("rate limit must not be negative")
+	}
+
+	// Resolve rate limit burst: CLI flag > env var > default
+	if *rateBurst != 0 {
+		config.RateBurst = *rateBurst
+	} else if envBurst := os.Getenv("RATE_BURST"); envBurst != "" {
+		burst, err := strconv.Atoi(envBurst)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit burst '%s': %w", envBurst, err)
+		}
+		config.RateBurst = burst
+	} else {
+		config.RateBurst = int(math.Ceil(config.RateLimit))
+	}
+	if config.RateBurst < 0 {
+		return nil, fmt.Errorf("rate limit burst must not be negative")
+	}
+
+	// Resolve API keys: CLI flag > env var > default
+	keys := *apiKeys
+	if keys == "" {
+		keys = os.Getenv("API_KEYS")
+	}
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			config.APIKeys = append(config.APIKeys, key)
+		}
+	}
+
+	// Resolve CORS origins: CLI flag > env var > default
+	origins := *corsOrigins
+	if origins == "" {
+		origins = os.Getenv("CORS_ORIGINS")
+	}
+	for _, origin := range strings.Split(origins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			config.CORSOrigins = append(config.CORSOrigins, origin)
+		}
+	}
+
 	return config, nil
 }
 
@@ -2,18 +2,41 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/jhoffmann/dailies/models"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-// SetupDatabase initializes and configures the SQLite database connection.
-// It takes a dbPath parameter specifying the path to the database file,
-// runs migrations, and returns a configured GORM database instance.
-func SetupDatabase(dbPath string) (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+// sqliteBusyTimeout is how long, in milliseconds, a SQLite connection waits for a lock
+// held by another connection before failing with "database is locked".
+const sqliteBusyTimeout = 5000
+
+// Database drivers accepted by SetupDatabase.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// SetupDatabase initializes and configures the database connection for the given
+// driver. For SQLite the DSN is the path to the database file, whose missing parent
+// directories are created, and every connection uses WAL journal mode and a busy timeout
+// so concurrent readers and writers wait for each other rather than failing; for
+// PostgreSQL it is a connection string or URL. It runs migrations and returns a
+// configured GORM database instance.
+func SetupDatabase(driver, dsn string) (*gorm.DB, error) {
+	dialector, err := openDialector(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
@@ -25,19 +48,104 @@ func SetupDatabase(dbPath string) (*gorm.DB, error) {
 	return db, nil
 }
 
+// openDialector returns the GORM dialector for the given driver and DSN.
+func openDialector(driver, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case DriverSQLite:
+		if err := ensureDataDir(dsn); err != nil {
+			return nil, err
+		}
+		return sqlite.Open(sqliteDSN(dsn)), nil
+	case DriverPostgres:
+		return postgres.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver '%s'", driver)
+	}
+}
+
+// sqliteDSN adds the WAL journal mode and busy timeout to a SQLite DSN, which the driver
+// applies as PRAGMAs on every new connection. Settings already present in the DSN are
+// kept, and in-memory databases keep their default journal mode.
+func sqliteDSN(dsn string) string {
+	var params []string
+	// The driver also accepts the shorter _timeout and _journal parameter names
+	if !strings.Contains(dsn, "_timeout=") {
+		params = append(params, fmt.Sprintf("_busy_timeout=%d", sqliteBusyTimeout))
+	}
+	inMemory := strings.Contains(dsn, ":memory:") || strings.Contains(dsn, "mode=memory")
+	if !strings.Contains(dsn, "_journal") && !inMemory {
+		params = append(params, "_journal_mode=WAL")
+	}
+	if len(params) == 0 {
+		return dsn
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + strings.Join(params, "&")
+}
+
+// ConfigurePool sets the maximum number of open and idle connections in the database
+// connection pool. Zero keeps the database/sql default for either setting.
+func ConfigurePool(db *gorm.DB, maxOpen, maxIdle int) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if maxOpen > 0 {
+		sqlDB.SetMaxOpenConns(maxOpen)
+	}
+	if maxIdle > 0 {
+		sqlDB.SetMaxIdleConns(maxIdle)
+	}
+	return nil
+}
+
+// ensureDataDir creates the parent directory of the database file if it does not exist.
+func ensureDataDir(dbPath string) error {
+	if dbPath == ":memory:" {
+		return nil
+	}
+
+	dir := filepath.Dir(dbPath)
+	if dir == "." {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory '%s': %w", dir, err)
+	}
+	return nil
+}
+
 // migrate runs database migrations for all models and creates necessary indexes.
 func migrate(db *gorm.DB) error {
 	log.Println("Running database migrations...")
 
+	// Runs first, as rebuilding a SQLite table drops the indexes AutoMigrate creates
+	if err := migratePriorityConstraint(db); err != nil {
+		return err
+	}
+
 	err := db.AutoMigrate(
 		&models.Frequency{},
 		&models.Tag{},
 		&models.Task{},
+		&models.TaskNote{},
+		&models.Snapshot{},
+		&models.SnapshotTask{},
+		&models.TaskResetEvent{},
 	)
 	if err != nil {
 		return err
 	}
 
+	if err := renameDuplicateTagNames(db); err != nil {
+		return err
+	}
+
 	if err := addIndexes(db); err != nil {
 		return err
 	}
@@ -46,6 +154,76 @@ func migrate(db *gorm.DB) error {
 	return nil
 }
 
+// renameDuplicateTagNames renames tags whose names differ from an older tag's only by
+// case, so tag names can be made unique case-insensitively. Later tags get a numeric
+// suffix, such as "work (2)". Tags in the trash are included as they keep their names.
+func renameDuplicateTagNames(db *gorm.DB) error {
+	var tags []models.Tag
+	if err := db.Unscoped().Order("created_at, id").Find(&tags).Error; err != nil {
+		return err
+	}
+
+	taken := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		taken[strings.ToLower(tag.Name)] = true
+	}
+
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		lower := strings.ToLower(tag.Name)
+		if !seen[lower] {
+			seen[lower] = true
+			continue
+		}
+
+		name := tag.Name
+		for n := 2; taken[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s (%d)", tag.Name, n)
+		}
+		taken[strings.ToLower(name)] = true
+		seen[strings.ToLower(name)] = true
+
+		if err := db.Unscoped().Model(&tag).UpdateColumn("name", name).Error; err != nil {
+			return err
+		}
+		log.Printf("Renamed tag '%s' to '%s', as its name differs from another tag's only by case", tag.Name, name)
+	}
+	return nil
+}
+
+// priorityConstraint is the name GORM gives the check constraint on task priorities.
+const priorityConstraint = "chk_tasks_priority"
+
+// migratePriorityConstraint replaces a task priority check constraint that still caps
+// priorities at 5, as created before the number of priority levels was configurable.
+// AutoMigrate leaves an existing constraint of the same name untouched.
+func migratePriorityConstraint(db *gorm.DB) error {
+	var definition string
+	switch db.Dialector.Name() {
+	case DriverSQLite:
+		if err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'tasks'").Scan(&definition).Error; err != nil {
+			return err
+		}
+	case DriverPostgres:
+		if err := db.Raw("SELECT pg_get_constraintdef(oid) FROM pg_constraint WHERE conname = ?", priorityConstraint).Scan(&definition).Error; err != nil {
+			return err
+		}
+	}
+	if !strings.Contains(definition, "priority <= 5") {
+		return nil
+	}
+
+	migrator := db.Migrator()
+	if err := migrator.DropConstraint(&models.Task{}, priorityConstraint); err != nil {
+		return err
+	}
+	if err := migrator.CreateConstraint(&models.Task{}, priorityConstraint); err != nil {
+		return err
+	}
+	log.Println("Removed the upper bound from the task priority constraint")
+	return nil
+}
+
 // addIndexes creates database indexes to improve query performance.
 func addIndexes(db *gorm.DB) error {
 	// Single column indexes
@@ -82,6 +260,11 @@ func addIndexes(db *gorm.DB) error {
 		return err
 	}
 
+	// Tag names are unique regardless of case
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name_lower ON tags(LOWER(name))").Error; err != nil {
+		return err
+	}
+
 	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_frequencies_name ON frequencies(name)").Error; err != nil {
 		return err
 	}
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/jhoffmann/dailies/models"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -12,8 +18,10 @@ func TestSetupDatabase(t *testing.T) {
 	// Use a temporary file for testing
 	tempFile := "test_database.db"
 	defer os.Remove(tempFile)
+	defer os.Remove(tempFile + "-wal")
+	defer os.Remove(tempFile + "-shm")
 
-	db, err := SetupDatabase(tempFile)
+	db, err := SetupDatabase(DriverSQLite, tempFile)
 	if err != nil {
 		t.Fatalf("SetupDatabase failed: %v", err)
 	}
@@ -35,7 +43,7 @@ func TestSetupDatabase(t *testing.T) {
 }
 
 func TestSetupDatabaseWithInMemory(t *testing.T) {
-	db, err := SetupDatabase(":memory:")
+	db, err := SetupDatabase(DriverSQLite, ":memory:")
 	if err != nil {
 		t.Fatalf("SetupDatabase with in-memory failed: %v", err)
 	}
@@ -56,14 +64,41 @@ func TestSetupDatabaseWithInMemory(t *testing.T) {
 	}
 }
 
+func TestSetupDatabaseWithNestedDirectory(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "var", "lib", "dailies", "dailies.db")
+
+	db, err := SetupDatabase(DriverSQLite, dbPath)
+	if err != nil {
+		t.Fatalf("SetupDatabase with nested directory failed: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to get SQL DB: %v", err)
+	}
+	defer sqlDB.Close()
+
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		t.Error("Expected database file to be created in nested directory")
+	}
+}
+
 func TestSetupDatabaseWithInvalidPath(t *testing.T) {
-	// Try to create database in non-existent directory
-	invalidPath := "/non/existent/directory/test.db"
+	// Use a regular file as a parent directory so it cannot be created
+	parent := filepath.Join(t.TempDir(), "not-a-directory")
+	if err := os.WriteFile(parent, []byte{}, 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	invalidPath := filepath.Join(parent, "test.db")
 
-	_, err := SetupDatabase(invalidPath)
+	_, err := SetupDatabase(DriverSQLite, invalidPath)
 	if err == nil {
 		t.Error("Expected error for invalid database path, got nil")
 	}
+
+	if err != nil && !strings.Contains(err.Error(), "failed to create data directory") {
+		t.Errorf("Expected data directory error, got: %v", err)
+	}
 }
 
 func TestMigrate(t *testing.T) {
@@ -79,7 +114,7 @@ func TestMigrate(t *testing.T) {
 	}
 
 	// Verify tables were created
-	tables := []string{"tasks", "frequencies", "tags", "task_tags"}
+	tables := []string{"tasks", "frequencies", "tags", "task_tags", "task_notes", "snapshots", "snapshot_tasks"}
 	for _, table := range tables {
 		if !db.Migrator().HasTable(table) {
 			t.Errorf("Expected table %s to exist", table)
@@ -109,6 +144,225 @@ func TestAddIndexes(t *testing.T) {
 	}
 }
 
+func TestMigrateRenamesDuplicateTagNames(t *testing.T) {
+	db, err := gorm.Open(sqliteDialector(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Tag{}); err != nil {
+		t.Fatalf("Failed to migrate tags: %v", err)
+	}
+
+	now := time.Now()
+	for i, name := range []string{"Work", "work", "work (2)", "WORK"} {
+		db.Create(&models.Tag{Name: name, Color: "#ff0000", CreatedAt: now.Add(time.Duration(i) * time.Minute)})
+	}
+
+	if err := migrate(db); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+
+	var names []string
+	db.Model(&models.Tag{}).Order("created_at").Pluck("name", &names)
+	expected := []string{"Work", "work (3)", "work (2)", "WORK (4)"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected tags %v, got %v", expected, names)
+	}
+
+	if err := db.Create(&models.Tag{Name: "wORK", Color: "#ff0000"}).Error; err == nil {
+		t.Error("Expected a tag name differing only by case to be rejected")
+	}
+}
+
+// baselineTask mirrors the tasks table as created before priority levels were
+// configurable, when the priority check constraint capped priorities at 5.
+type baselineTask struct {
+	ID       string `gorm:"type:text;primaryKey"`
+	Name     string `gorm:"not null"`
+	Priority *int   `gorm:"check:priority >= 1 AND priority <= 5"`
+}
+
+// TableName stores baselineTask records in the tasks table.
+func (baselineTask) TableName() string {
+	return "tasks"
+}
+
+func TestMigratePriorityConstraint(t *testing.T) {
+	db, err := gorm.Open(sqliteDialector(filepath.Join(t.TempDir(), "baseline.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&baselineTask{}); err != nil {
+		t.Fatalf("Failed to create baseline schema: %v", err)
+	}
+	priority := 3
+	if err := db.Create(&baselineTask{ID: "existing", Name: "Existing", Priority: &priority}).Error; err != nil {
+		t.Fatalf("Failed to create baseline task: %v", err)
+	}
+
+	if err := migrate(db); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+
+	high := 8
+	if err := db.Create(&models.Task{Name: "High", Priority: &high}).Error; err != nil {
+		t.Errorf("Expected a priority above 5 to be accepted, got %v", err)
+	}
+	zero := 0
+	if err := db.Create(&models.Task{Name: "Zero", Priority: &zero}).Error; err == nil {
+		t.Error("Expected a priority below 1 to be rejected")
+	}
+
+	var existing models.Task
+	if err := db.First(&existing, "id = ?", "existing").Error; err != nil || existing.Priority == nil || *existing.Priority != 3 {
+		t.Errorf("Expected the existing task to be kept, got %+v (%v)", existing, err)
+	}
+
+	for _, index := range []string{"idx_tasks_priority", "ParentID"} {
+		if !db.Migrator().HasIndex(&models.Task{}, index) {
+			t.Errorf("Expected task index %s to be recreated", index)
+		}
+	}
+
+	// Migrating again leaves the updated constraint alone
+	if err := migrate(db); err != nil {
+		t.Fatalf("Second migrate failed: %v", err)
+	}
+}
+
+func TestSetupDatabaseJournalMode(t *testing.T) {
+	db, err := SetupDatabase(DriverSQLite, filepath.Join(t.TempDir(), "dailies.db"))
+	if err != nil {
+		t.Fatalf("SetupDatabase failed: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	var mode string
+	if err := db.Raw("PRAGMA journal_mode").Scan(&mode).Error; err != nil {
+		t.Fatalf("Failed to read journal mode: %v", err)
+	}
+	if !strings.EqualFold(mode, "wal") {
+		t.Errorf("Expected WAL journal mode, got %s", mode)
+	}
+
+	var timeout int
+	if err := db.Raw("PRAGMA busy_timeout").Scan(&timeout).Error; err != nil {
+		t.Fatalf("Failed to read busy timeout: %v", err)
+	}
+	if timeout != sqliteBusyTimeout {
+		t.Errorf("Expected busy timeout %d, got %d", sqliteBusyTimeout, timeout)
+	}
+}
+
+func TestSetupDatabaseConcurrentWrites(t *testing.T) {
+	db, err := SetupDatabase(DriverSQLite, filepath.Join(t.TempDir(), "dailies.db"))
+	if err != nil {
+		t.Fatalf("SetupDatabase failed: %v", err)
+	}
+	if err := ConfigurePool(db, 8, 8); err != nil {
+		t.Fatalf("ConfigurePool failed: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	const workers, writes = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*writes)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				task := models.Task{Name: fmt.Sprintf("Task %d-%d", w, i)}
+				if err := db.Create(&task).Error; err != nil {
+					errs <- err
+					continue
+				}
+				if err := db.Model(&task).Update("completed", true).Error; err != nil {
+					errs <- err
+				}
+				var count int64
+				if err := db.Model(&models.Task{}).Count(&count).Error; err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Concurrent access failed: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.Task{}).Where("completed = ?", true).Count(&count)
+	if count != workers*writes {
+		t.Errorf("Expected %d completed tasks, got %d", workers*writes, count)
+	}
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	tests := []struct {
+		dsn      string
+		expected string
+	}{
+		{"dailies.db", "dailies.db?_busy_timeout=5000&_journal_mode=WAL"},
+		{":memory:", ":memory:?_busy_timeout=5000"},
+		{"file:dailies.db?cache=shared", "file:dailies.db?cache=shared&_busy_timeout=5000&_journal_mode=WAL"},
+		{"dailies.db?_timeout=100&_journal=DELETE", "dailies.db?_timeout=100&_journal=DELETE"},
+	}
+
+	for _, tt := range tests {
+		if got := sqliteDSN(tt.dsn); got != tt.expected {
+			t.Errorf("sqliteDSN(%q) = %q, expected %q", tt.dsn, got, tt.expected)
+		}
+	}
+}
+
+func TestConfigurePool(t *testing.T) {
+	db, err := SetupDatabase(DriverSQLite, ":memory:")
+	if err != nil {
+		t.Fatalf("SetupDatabase failed: %v", err)
+	}
+	if err := ConfigurePool(db, 4, 0); err != nil {
+		t.Fatalf("ConfigurePool failed: %v", err)
+	}
+
+	sqlDB, _ := db.DB()
+	if max := sqlDB.Stats().MaxOpenConnections; max != 4 {
+		t.Errorf("Expected 4 maximum open connections, got %d", max)
+	}
+}
+
+func TestSetupDatabaseWithUnsupportedDriver(t *testing.T) {
+	if _, err := SetupDatabase("mysql", "dailies.db"); err == nil {
+		t.Error("Expected an error for an unsupported driver")
+	}
+}
+
+func TestOpenDialector(t *testing.T) {
+	tests := []struct {
+		driver   string
+		dsn      string
+		expected string
+	}{
+		{DriverSQLite, ":memory:", "sqlite"},
+		{DriverPostgres, "postgres://localhost/dailies", "postgres"},
+	}
+
+	for _, tt := range tests {
+		dialector, err := openDialector(tt.driver, tt.dsn)
+		if err != nil {
+			t.Fatalf("openDialector(%s) failed: %v", tt.driver, err)
+		}
+		if name := dialector.Name(); name != tt.expected {
+			t.Errorf("Expected %s dialector, got %s", tt.expected, name)
+		}
+	}
+}
+
 // Helper function to create SQLite dialector for testing
 func sqliteDialector(dsn string) gorm.Dialector {
 	return sqlite.Open(dsn)
@@ -15,21 +15,28 @@ while getopts "t:" opt; do
 done
 
 # Build API URL
-API_URL="http://localhost:9002/api/tasks?sort=priority&completed=false"
+# The tooltip lists up to the largest page the API allows; the count covers every task
+API_URL="http://localhost:9002/api/tasks?sort=priority&completed=false&limit=1000"
 if [ -n "$TAGS" ]; then
   API_URL="${API_URL}&tag=${TAGS}"
 fi
 
-# Query API with httpie (suppress output on error)
-RESPONSE=$(http --check-status --ignore-stdin --timeout=2 "$API_URL" 2>/dev/null)
+# Query API with httpie, printing headers and body (suppress output on error)
+OUTPUT=$(http --check-status --ignore-stdin --timeout=2 --print=hb "$API_URL" 2>/dev/null)
 
 # Exit silently if httpie failed (API offline or error)
 if [ $? -ne 0 ]; then
   exit 0
 fi
 
-# Get task count
-TASK_COUNT=$(echo "$RESPONSE" | jq 'length')
+# The body follows the first blank line after the headers
+RESPONSE=$(echo "$OUTPUT" | sed '1,/^\r\?$/d')
+
+# Get task count from the total reported by the API, falling back to the page length
+TASK_COUNT=$(echo "$OUTPUT" | tr -d '\r' | awk -F': ' 'tolower($1) == "x-total-count" { print $2; exit }')
+if [ -z "$TASK_COUNT" ]; then
+  TASK_COUNT=$(echo "$RESPONSE" | jq 'length')
+fi
 
 # Exit silently if no tasks found
 if [ "$TASK_COUNT" -eq 0 ]; then
@@ -155,11 +155,11 @@ export class FrequenciesComponent implements OnInit, OnDestroy {
     frequency.editPeriod = undefined;
   }
 
-  deleteFrequency(frequencyId: string) {
-    if (!confirm('Are you sure you want to delete this frequency?')) return;
+  deleteFrequency(frequencyId: string, force = false) {
+    if (!force && !confirm('Are you sure you want to delete this frequency?')) return;
 
     this.apiService
-      .deleteFrequency(frequencyId)
+      .deleteFrequency(frequencyId, force)
       .pipe(takeUntil(this.destroy$))
       .subscribe({
         next: () => {
@@ -167,6 +167,14 @@ export class FrequenciesComponent implements OnInit, OnDestroy {
           this.apiService.notifyFrequenciesChanged();
         },
         error: (error) => {
+          // Frequencies still used by tasks need a second confirmation
+          if (error.status === 409) {
+            const count = error.error?.task_count;
+            if (confirm(`This frequency is used by ${count} tasks. Remove it from them and delete it?`)) {
+              this.deleteFrequency(frequencyId, true);
+            }
+            return;
+          }
           console.error('Error deleting frequency:', error);
           alert('Error deleting frequency: ' + (error.error?.error || 'Unknown error'));
         },
@@ -29,6 +29,7 @@ import { TaskFilters } from '../../models/task-filters';
 export class TasksComponent implements OnInit, OnDestroy {
   private destroy$ = new Subject<void>();
   private timerRefreshInterval?: number;
+  private draggedTaskId?: string;
 
   tasks: Task[] = [];
   filteredTasks: Task[] = [];
@@ -105,6 +106,7 @@ export class TasksComponent implements OnInit, OnDestroy {
         case 'task_create':
         case 'task_update':
         case 'task_delete':
+        case 'task_list_refresh':
           this.loadTasks();
           break;
         case 'tag_create':
@@ -136,8 +138,9 @@ export class TasksComponent implements OnInit, OnDestroy {
     if (this.filters.tag) params.tag_ids = this.filters.tag;
     if (this.filters.sort) params.sort = this.filters.sort;
 
+    // The list is not paged, so fetch every page of matching tasks
     this.apiService
-      .getTasks(params)
+      .getAllTasks(params)
       .pipe(takeUntil(this.destroy$))
       .subscribe({
         next: (tasks) => {
@@ -235,6 +238,41 @@ export class TasksComponent implements OnInit, OnDestroy {
 
       return true;
     });
+
+    if (this.filters.sort === 'position') {
+      this.filteredTasks.sort((a, b) => (a.position ?? 0) - (b.position ?? 0));
+    }
+  }
+
+  get manualOrder(): boolean {
+    return this.filters.sort === 'position';
+  }
+
+  onDragStart(task: Task) {
+    this.draggedTaskId = task.id;
+  }
+
+  onDrop(target: Task) {
+    const draggedId = this.draggedTaskId;
+    this.draggedTaskId = undefined;
+    if (!draggedId || draggedId === target.id) return;
+
+    const ordered = this.filteredTasks.filter((task) => task.id !== draggedId);
+    const dragged = this.filteredTasks.find((task) => task.id === draggedId);
+    if (!dragged) return;
+    ordered.splice(ordered.indexOf(target), 0, dragged);
+    this.filteredTasks = ordered;
+
+    this.apiService
+      .reorderTasks(ordered.map((task) => task.id))
+      .pipe(takeUntil(this.destroy$))
+      .subscribe({
+        error: (error) => {
+          console.error('Error reordering tasks:', error);
+          this.loadTasks();
+          alert('Error reordering tasks: ' + (error.error?.error || 'Unknown error'));
+        },
+      });
   }
 
   updateFilters(key: keyof TaskFilters, value: any) {
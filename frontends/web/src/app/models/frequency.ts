@@ -5,7 +5,12 @@ export interface Frequency {
   name: string;
   period: string;
   reset: string;
+  enabled?: boolean;
+  reset_mode?: 'reuse' | 'clone';
+  reminder_lead_minutes?: number;
   tasks?: Task[];
+  task_count?: number;
+  next_reset?: string;
   created_at?: string;
   updated_at?: string;
   // Dynamic edit properties
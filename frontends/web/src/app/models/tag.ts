@@ -5,6 +5,7 @@ export interface Tag {
   name: string;
   color: string;
   tasks?: Task[];
+  task_count?: number;
   created_at?: string;
   updated_at?: string;
   // Dynamic edit properties
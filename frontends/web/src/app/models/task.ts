@@ -10,6 +10,8 @@ export interface Task {
   frequency_id?: string;
   frequency?: Frequency;
   tags: Tag[];
+  position?: number;
+  source?: string;
   created_at?: string;
   updated_at?: string;
   // Dynamic edit properties
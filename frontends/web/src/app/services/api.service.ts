@@ -1,6 +1,6 @@
 import { Injectable } from '@angular/core';
-import { HttpClient, HttpParams } from '@angular/common/http';
-import { Observable, Subject } from 'rxjs';
+import { HttpClient, HttpParams, HttpResponse } from '@angular/common/http';
+import { EMPTY, Observable, Subject, expand, map, reduce } from 'rxjs';
 import { Task } from '../models/task';
 import { Tag } from '../models/tag';
 import { Frequency } from '../models/frequency';
@@ -13,6 +13,8 @@ import { environment } from '../../environments/environment';
 })
 export class ApiService {
   private readonly API_BASE = `http://${environment.apiHost}:${environment.apiPort}/api`;
+  // Largest page of tasks the API returns in a single request
+  private readonly TASK_PAGE_SIZE = 1000;
 
   // Event subjects for cross-component communication
   private tagsChangedSubject = new Subject<void>();
@@ -25,6 +27,10 @@ export class ApiService {
 
   // Generic HTTP methods
   private get<T>(endpoint: string, params?: any): Observable<T> {
+    return this.http.get<T>(`${this.API_BASE}${endpoint}`, { params: this.buildParams(params) });
+  }
+
+  private buildParams(params?: any): HttpParams {
     let httpParams = new HttpParams();
     if (params) {
       Object.keys(params).forEach((key) => {
@@ -33,7 +39,7 @@ export class ApiService {
         }
       });
     }
-    return this.http.get<T>(`${this.API_BASE}${endpoint}`, { params: httpParams });
+    return httpParams;
   }
 
   private post<T>(endpoint: string, data: any): Observable<T> {
@@ -53,8 +59,34 @@ export class ApiService {
     return this.get<Task[]>('/tasks', params);
   }
 
+  // Fetches every task matching params, following X-Total-Count across pages
+  getAllTasks(params: any = {}): Observable<Task[]> {
+    const fetchPage = (offset: number) =>
+      this.http
+        .get<Task[]>(`${this.API_BASE}/tasks`, {
+          params: this.buildParams({ ...params, limit: this.TASK_PAGE_SIZE, offset }),
+          observe: 'response',
+        })
+        .pipe(map((response) => ({ response, offset })));
+
+    return fetchPage(0).pipe(
+      expand(({ response, offset }) => {
+        const tasks = response.body || [];
+        const next = offset + tasks.length;
+        const total = Number(response.headers.get('X-Total-Count'));
+        const more = tasks.length === this.TASK_PAGE_SIZE && (isNaN(total) || next < total);
+        return more ? fetchPage(next) : EMPTY;
+      }),
+      reduce(
+        (all: Task[], { response }: { response: HttpResponse<Task[]> }) =>
+          all.concat(response.body || []),
+        [],
+      ),
+    );
+  }
+
   createTask(task: Partial<Task>): Observable<Task> {
-    return this.post<Task>('/tasks', task);
+    return this.post<Task>('/tasks', { source: 'web', ...task });
   }
 
   updateTask(id: string, task: Partial<Task>): Observable<Task> {
@@ -65,6 +97,10 @@ export class ApiService {
     return this.delete<void>(`/tasks/${id}`);
   }
 
+  reorderTasks(taskIds: string[]): Observable<Task[]> {
+    return this.put<Task[]>('/tasks/reorder', { task_ids: taskIds });
+  }
+
   // Tag API methods
   getTags(): Observable<Tag[]> {
     return this.get<Tag[]>('/tags');
@@ -95,8 +131,8 @@ export class ApiService {
     return this.put<Frequency>(`/frequencies/${id}`, frequency);
   }
 
-  deleteFrequency(id: string): Observable<void> {
-    return this.delete<void>(`/frequencies/${id}`);
+  deleteFrequency(id: string, force = false): Observable<void> {
+    return this.delete<void>(`/frequencies/${id}${force ? '?force=true' : ''}`);
   }
 
   // Timer methods
@@ -11,6 +11,7 @@ export interface WebSocketEvent {
     | 'task_update'
     | 'task_create'
     | 'task_delete'
+    | 'task_list_refresh'
     | 'tag_update'
     | 'tag_create'
     | 'tag_delete'
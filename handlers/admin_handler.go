@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ExportDatabase returns a handler function that downloads a consistent snapshot of the
+// SQLite database. The snapshot is written with VACUUM INTO, so concurrent writes
+// cannot leave it half updated.
+func ExportDatabase(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dir, err := os.MkdirTemp("", "dailies-export-")
+		if err != nil {
+			log.Println("Error creating export directory:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export database"})
+			return
+		}
+		defer os.RemoveAll(dir)
+
+		snapshot := filepath.Join(dir, "dailies.db")
+		if err := db.Exec("VACUUM INTO ?", snapshot).Error; err != nil {
+			log.Println("Error exporting database:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export database"})
+			return
+		}
+
+		filename := fmt.Sprintf("dailies-%s.db", time.Now().Format("20060102-150405"))
+		c.Header("Content-Type", "application/vnd.sqlite3")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.File(snapshot)
+	}
+}
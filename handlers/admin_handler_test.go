@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestExportDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+	db.Create(&models.Task{Name: "Exported task"})
+
+	r := gin.New()
+	r.GET("/admin/export.db", ExportDatabase(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/export.db", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/vnd.sqlite3" {
+		t.Errorf("Expected SQLite content type, got '%s'", contentType)
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment;") {
+		t.Errorf("Expected attachment disposition, got '%s'", disposition)
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := os.WriteFile(path, w.Body.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+
+	snapshot, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open snapshot: %v", err)
+	}
+	sqlDB, _ := snapshot.DB()
+	defer sqlDB.Close()
+
+	for _, table := range []string{"tasks", "tags", "frequencies", "task_tags"} {
+		if !snapshot.Migrator().HasTable(table) {
+			t.Errorf("Expected snapshot to contain table '%s'", table)
+		}
+	}
+
+	var task models.Task
+	if err := snapshot.First(&task, "name = ?", "Exported task").Error; err != nil {
+		t.Errorf("Expected snapshot to contain the task: %v", err)
+	}
+}
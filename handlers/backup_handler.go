@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// backupVersion is the version of the backup document schema written by ExportBackup.
+const backupVersion = 1
+
+// backupBatchSize is the number of records upserted per statement when importing.
+const backupBatchSize = 500
+
+// Backup represents a full JSON backup of tasks, tags, frequencies and the task-tag
+// associations. Deleted tasks are included; tags in the trash are not.
+type Backup struct {
+	Version     int                `json:"version"`
+	ExportedAt  time.Time          `json:"exported_at"`
+	Frequencies []models.Frequency `json:"frequencies"`
+	Tags        []models.Tag       `json:"tags"`
+	Tasks       []models.Task      `json:"tasks"`
+	TaskTags    []BackupTaskTag    `json:"task_tags"`
+}
+
+// BackupTaskTag represents a single association between a task and a tag in a backup.
+type BackupTaskTag struct {
+	TaskID string `json:"task_id"`
+	TagID  string `json:"tag_id"`
+}
+
+// ImportResult reports how many records were restored from a backup, along with the
+// conflicts that were resolved or skipped.
+type ImportResult struct {
+	Frequencies int      `json:"frequencies"`
+	Tags        int      `json:"tags"`
+	Tasks       int      `json:"tasks"`
+	TaskTags    int      `json:"task_tags"`
+	Conflicts   []string `json:"conflicts"`
+}
+
+// ExportBackup returns a handler function that streams all tasks, tags, frequencies and
+// their associations as a JSON document that ImportBackup can restore.
+func ExportBackup(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		backup := Backup{
+			Version:     backupVersion,
+			ExportedAt:  time.Now(),
+			Frequencies: []models.Frequency{},
+			Tags:        []models.Tag{},
+			Tasks:       []models.Task{},
+			TaskTags:    []BackupTaskTag{},
+		}
+
+		if err := db.Order("created_at").Find(&backup.Frequencies).Error; err != nil {
+			log.Println("Error fetching frequencies:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+			return
+		}
+		if err := db.Order("created_at").Find(&backup.Tags).Error; err != nil {
+			log.Println("Error fetching tags:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+			return
+		}
+		if err := db.Order("created_at").Find(&backup.Tasks).Error; err != nil {
+			log.Println("Error fetching tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+			return
+		}
+		if err := db.Table("task_tags").
+			Select("task_tags.task_id, task_tags.tag_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("tags.deleted_at IS NULL").
+			Order("task_tags.task_id, task_tags.tag_id").
+			Scan(&backup.TaskTags).Error; err != nil {
+			log.Println("Error fetching task tags:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+			return
+		}
+
+		filename := fmt.Sprintf("dailies-%s.json", time.Now().Format("20060102-150405"))
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Status(http.StatusOK)
+		if err := json.NewEncoder(c.Writer).Encode(backup); err != nil {
+			log.Println("Error writing backup:", err)
+		}
+	}
+}
+
+// ImportBackup returns a handler function that restores a backup written by ExportBackup
+// in a single transaction. Records are upserted by ID. A frequency or tag whose name is
+// already used by a different record is not imported; references to it are pointed at the
+// existing record instead. Associations with unknown tasks or tags are skipped. Both cases
+// are reported as conflicts.
+func ImportBackup(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var backup Backup
+		if err := c.ShouldBindJSON(&backup); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if backup.Version != backupVersion {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported backup version %d", backup.Version)})
+			return
+		}
+
+		result := ImportResult{Conflicts: []string{}}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Disabled flags are collected up front, as inserting fills in column defaults
+			frequencyIDs := make(map[string]string)
+			var frequencies []models.Frequency
+			var paused []string
+			for _, frequency := range backup.Frequencies {
+				var existing models.Frequency
+				err := tx.Where("name = ? AND id <> ?", frequency.Name, frequency.ID).First(&existing).Error
+				if err == nil {
+					frequencyIDs[frequency.ID] = existing.ID
+					result.Conflicts = append(result.Conflicts, fmt.Sprintf("Frequency '%s' already exists, using the existing frequency", frequency.Name))
+					continue
+				}
+				if err != gorm.ErrRecordNotFound {
+					return err
+				}
+				frequencyIDs[frequency.ID] = frequency.ID
+				frequencies = append(frequencies, frequency)
+				if !frequency.Enabled {
+					paused = append(paused, frequency.ID)
+				}
+			}
+			if err := upsertBackupRecords(tx, frequencies, "Tasks"); err != nil {
+				return err
+			}
+			if err := disableBackupRecords(tx, &models.Frequency{}, "enabled", paused); err != nil {
+				return err
+			}
+			result.Frequencies = len(frequencies)
+
+			tagIDs := make(map[string]string)
+			var tags []models.Tag
+			var muted []string
+			for _, tag := range backup.Tags {
+				// Tags in the trash still hold their name
+				var existing models.Tag
+				err := tx.Unscoped().Where("LOWER(name) = LOWER(?) AND id <> ?", tag.Name, tag.ID).First(&existing).Error
+				if err == nil {
+					tagIDs[tag.ID] = existing.ID
+					result.Conflicts = append(result.Conflicts, fmt.Sprintf("Tag '%s' already exists, using the existing tag", tag.Name))
+					continue
+				}
+				if err != gorm.ErrRecordNotFound {
+					return err
+				}
+				tagIDs[tag.ID] = tag.ID
+				tags = append(tags, tag)
+				if !tag.NotificationsEnabled {
+					muted = append(muted, tag.ID)
+				}
+			}
+			if err := upsertBackupRecords(tx, tags, "Tasks"); err != nil {
+				return err
+			}
+			if err := disableBackupRecords(tx, &models.Tag{}, "notifications_enabled", muted); err != nil {
+				return err
+			}
+			result.Tags = len(tags)
+
+			for i := range backup.Tasks {
+				task := &backup.Tasks[i]
+				if task.FrequencyID == nil {
+					continue
+				}
+				if id, ok := frequencyIDs[*task.FrequencyID]; ok {
+					task.FrequencyID = &id
+					continue
+				}
+				var count int64
+				if err := tx.Model(&models.Frequency{}).Where("id = ?", *task.FrequencyID).Count(&count).Error; err != nil {
+					return err
+				}
+				if count == 0 {
+					result.Conflicts = append(result.Conflicts, fmt.Sprintf("Task '%s' references an unknown frequency, importing it without one", task.Name))
+					task.FrequencyID = nil
+				}
+			}
+			if err := upsertBackupRecords(tx, backup.Tasks, "Frequency", "Tags", "Subtasks"); err != nil {
+				return err
+			}
+			result.Tasks = len(backup.Tasks)
+
+			for _, link := range backup.TaskTags {
+				tagID, ok := tagIDs[link.TagID]
+				if !ok {
+					tagID = link.TagID
+				}
+
+				var tasks, tags int64
+				if err := tx.Model(&models.Task{}).Where("id = ?", link.TaskID).Count(&tasks).Error; err != nil {
+					return err
+				}
+				if err := tx.Unscoped().Model(&models.Tag{}).Where("id = ?", tagID).Count(&tags).Error; err != nil {
+					return err
+				}
+				if tasks == 0 || tags == 0 {
+					result.Conflicts = append(result.Conflicts, fmt.Sprintf("Skipped association of task %s with unknown tag or task %s", link.TaskID, link.TagID))
+					continue
+				}
+
+				if err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", link.TaskID, tagID).Error; err != nil {
+					return err
+				}
+				result.TaskTags++
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("Error importing backup:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import data"})
+			return
+		}
+
+		// Broadcast a single WebSocket event for the whole import
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_list_refresh", result)
+			}
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// upsertBackupRecords inserts records, replacing any with the same ID, without touching
+// the omitted associations. Existing records take every column from the backup, including
+// their timestamps.
+func upsertBackupRecords[T any](tx *gorm.DB, records []T, omit ...string) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(&records[0]); err != nil {
+		return err
+	}
+	var columns []string
+	for _, name := range stmt.Schema.DBNames {
+		if name != "id" {
+			columns = append(columns, name)
+		}
+	}
+
+	return tx.Omit(omit...).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns(columns),
+		}).
+		CreateInBatches(records, backupBatchSize).Error
+}
+
+// disableBackupRecords sets a boolean column to false for the given IDs after an upsert,
+// since column defaults replace false values on insert. Timestamps are left untouched.
+func disableBackupRecords(tx *gorm.DB, model any, column string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Model(model).Where("id IN ?", ids).UpdateColumn(column, false).Error
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+)
+
+func TestExportImportBackup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	source := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	source.Create(&frequency)
+	source.Model(&frequency).Update("enabled", false)
+	tag := models.Tag{Name: "home", Color: "#00cc66"}
+	source.Create(&tag)
+	task := models.Task{Name: "Water plants", Completed: true, FrequencyID: &frequency.ID, Tags: []models.Tag{tag}}
+	source.Create(&task)
+
+	r := gin.New()
+	r.GET("/export", ExportBackup(source))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/export", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment;") {
+		t.Errorf("Expected attachment disposition, got %q", w.Header().Get("Content-Disposition"))
+	}
+	exported := w.Body.Bytes()
+
+	var backup Backup
+	json.Unmarshal(exported, &backup)
+	if len(backup.Frequencies) != 1 || len(backup.Tags) != 1 || len(backup.Tasks) != 1 || len(backup.TaskTags) != 1 {
+		t.Fatalf("Expected one record of each kind, got %+v", backup)
+	}
+
+	// Restore into an empty database, where the existing "home" tag conflicts by name
+	target := setupTestHandlerDB(t)
+	existing := models.Tag{Name: "home", Color: "#ffffff"}
+	target.Create(&existing)
+
+	r = gin.New()
+	r.POST("/import", ImportBackup(target))
+
+	// Importing twice upserts rather than duplicating
+	var result ImportResult
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/import", bytes.NewReader(exported))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+	}
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Frequencies != 1 || result.Tags != 0 || result.Tasks != 1 || result.TaskTags != 1 {
+		t.Errorf("Unexpected import counts: %+v", result)
+	}
+	if len(result.Conflicts) != 1 {
+		t.Errorf("Expected the tag name conflict to be reported, got %v", result.Conflicts)
+	}
+
+	var restored models.Task
+	target.Preload("Tags").Preload("Frequency").First(&restored, "id = ?", task.ID)
+	if !restored.Completed || restored.Frequency == nil || restored.Frequency.ID != frequency.ID {
+		t.Errorf("Expected completed task with its frequency, got %+v", restored)
+	}
+	if restored.Frequency != nil && restored.Frequency.Enabled {
+		t.Error("Expected the paused frequency to stay paused")
+	}
+	if len(restored.Tags) != 1 || restored.Tags[0].ID != existing.ID {
+		t.Errorf("Expected task to carry the existing tag, got %+v", restored.Tags)
+	}
+
+	if !restored.UpdatedAt.Equal(backup.Tasks[0].UpdatedAt) {
+		t.Errorf("Expected updated_at %v to be restored, got %v", backup.Tasks[0].UpdatedAt, restored.UpdatedAt)
+	}
+
+	var count int64
+	target.Model(&models.Task{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 task after importing twice, got %d", count)
+	}
+}
+
+func TestImportBackupUnsupportedVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/import", ImportBackup(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/import", bytes.NewBufferString(`{"version": 99}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
+)
+
+// taskCSVHeader lists the columns written by ExportTasksCSV.
+var taskCSVHeader = []string{"id", "name", "completed", "priority", "frequency_name", "tags", "date_created", "date_modified"}
+
+// ExportTasksCSV returns a handler function that writes tasks as CSV for spreadsheets,
+// accepting the same filters as GetTasks. Tag names are joined with semicolons and
+// dates are written as RFC3339 timestamps.
+func ExportTasksCSV(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := db.Preload("Tags", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("tags.name")
+		}).Preload("Frequency").Where("deleted = ?", false)
+
+		query, err := filterTasks(c, query)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var tasks []models.Task
+		if err := query.Order("tasks.created_at ASC").Find(&tasks).Error; err != nil {
+			log.Println("Error fetching tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="tasks.csv"`)
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		w.Write(taskCSVHeader)
+		for _, task := range tasks {
+			w.Write(taskCSVRecord(task))
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			log.Println("Error writing tasks CSV:", err)
+		}
+	}
+}
+
+// taskCSVRecord converts a task into a CSV row matching taskCSVHeader.
+func taskCSVRecord(task models.Task) []string {
+	priority := ""
+	if task.Priority != nil {
+		priority = strconv.Itoa(*task.Priority)
+	}
+	frequency := ""
+	if task.Frequency != nil {
+		frequency = task.Frequency.Name
+	}
+	tags := make([]string, len(task.Tags))
+	for i, tag := range task.Tags {
+		tags[i] = tag.Name
+	}
+
+	return []string{
+		task.ID,
+		task.Name,
+		strconv.FormatBool(task.Completed),
+		priority,
+		frequency,
+		strings.Join(tags, ";"),
+		task.CreatedAt.Format(time.RFC3339),
+		task.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+)
+
+func TestExportTasksCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+	work := models.Tag{Name: "work", Color: "#111111"}
+	urgent := models.Tag{Name: "urgent", Color: "#222222"}
+	db.Create(&work)
+	db.Create(&urgent)
+
+	db.Create(&models.Task{Name: "Report, weekly", Priority: intPtr(2), FrequencyID: &frequency.ID, Tags: []models.Tag{work, urgent}})
+	db.Create(&models.Task{Name: "Done", Completed: true})
+
+	r := gin.New()
+	r.GET("/tasks.csv", ExportTasksCSV(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks.csv?completed=false", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "text/csv; charset=utf-8" {
+		t.Errorf("Expected CSV content type, got %q", contentType)
+	}
+	if disposition := w.Header().Get("Content-Disposition"); disposition != `attachment; filename="tasks.csv"` {
+		t.Errorf("Expected attachment disposition, got %q", disposition)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected header and 1 filtered row, got %d rows", len(records))
+	}
+	if records[0][0] != "id" || records[0][7] != "date_modified" {
+		t.Errorf("Unexpected header %v", records[0])
+	}
+
+	row := records[1]
+	expected := []string{"Report, weekly", "false", "2", "Daily", "urgent;work"}
+	for i, value := range expected {
+		if row[i+1] != value {
+			t.Errorf("Expected column %s to be %q, got %q", records[0][i+1], value, row[i+1])
+		}
+	}
+
+	// Invalid filters are rejected like GetTasks
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks.csv?min_streak=-1", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid filter, got %d", http.StatusBadRequest, w.Code)
+	}
+}
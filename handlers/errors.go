@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// isDuplicateKeyError reports whether err is a unique constraint violation, recognizing
+// the messages of both SQLite and PostgreSQL.
+func isDuplicateKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	message := err.Error()
+	return strings.Contains(message, "UNIQUE constraint failed") || // SQLite
+		strings.Contains(message, "duplicate key value") || // PostgreSQL
+		strings.Contains(message, "SQLSTATE 23505") // PostgreSQL unique_violation
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestIsDuplicateKeyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Nil", nil, false},
+		{"SQLite", errors.New("UNIQUE constraint failed: tags.name"), true},
+		{"PostgreSQL", errors.New(`ERROR: duplicate key value violates unique constraint "uni_tags_name" (SQLSTATE 23505)`), true},
+		{"PostgreSQL code only", errors.New("ERROR: unique violation (SQLSTATE 23505)"), true},
+		{"Translated", fmt.Errorf("creating tag: %w", gorm.ErrDuplicatedKey), true},
+		{"Other", errors.New("database is locked"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isDuplicateKeyError(tt.err); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
@@ -1,21 +1,25 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/metrics"
 	"github.com/jhoffmann/dailies/models"
-	"github.com/robfig/cron/v3"
 	"gorm.io/gorm"
 )
 
-// GetFrequencies returns a handler function for retrieving all frequencies with optional filtering.
-func GetFrequencies(db *gorm.DB) gin.HandlerFunc {
+// GetFrequencies returns a handler function for retrieving all frequencies with optional
+// filtering. Each frequency includes the next time its schedule fires in the specified
+// timezone, omitted when its cron expression is invalid.
+func GetFrequencies(db *gorm.DB, location *time.Location, timezone string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var frequencies []models.Frequency
+		frequencies := []models.Frequency{}
 		query := db.Model(&models.Frequency{})
 
 		// Filter by name (partial matching)
@@ -31,6 +35,16 @@ func GetFrequencies(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch frequencies"})
 			return
 		}
+		now := time.Now().In(location)
+		for i := range frequencies {
+			frequencies[i].TaskCount = frequencyTaskCount(frequencies[i])
+			if schedule, err := frequencies[i].Schedule(timezone); err == nil {
+				if next := schedule.Next(now); !next.IsZero() {
+					next = next.In(location)
+					frequencies[i].NextFire = &next
+				}
+			}
+		}
 
 		c.JSON(http.StatusOK, frequencies)
 	}
@@ -51,21 +65,57 @@ func GetFrequency(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch frequency"})
 			return
 		}
+		frequency.TaskCount = frequencyTaskCount(frequency)
 
 		c.JSON(http.StatusOK, frequency)
 	}
 }
 
+// GetFrequencyByName returns a handler function for retrieving the frequency whose name
+// matches exactly, ignoring case.
+func GetFrequencyByName(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.TrimSpace(c.Param("name"))
+		var frequency models.Frequency
+
+		if err := db.Preload("Tasks").Where("LOWER(name) = LOWER(?)", name).First(&frequency).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Frequency not found"})
+				return
+			}
+			log.Println("Error fetching frequency:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch frequency"})
+			return
+		}
+		frequency.TaskCount = frequencyTaskCount(frequency)
+
+		c.JSON(http.StatusOK, frequency)
+	}
+}
+
+// frequencyTaskCount returns the number of live tasks among a frequency's preloaded tasks.
+func frequencyTaskCount(frequency models.Frequency) int {
+	count := 0
+	for _, task := range frequency.Tasks {
+		if !task.Deleted {
+			count++
+		}
+	}
+	return count
+}
+
 // CreateFrequencyRequest represents the request payload for creating a frequency.
 type CreateFrequencyRequest struct {
-	Name   string `json:"name" binding:"required"`
-	Period string `json:"period" binding:"required"`
+	Name          string `json:"name" binding:"required"`
+	Period        string `json:"period" binding:"required"`
+	ResetBoundary string `json:"reset_boundary,omitempty"`
+	ResetMode     string `json:"reset_mode,omitempty"`
+	ReminderLead  *int   `json:"reminder_lead_minutes,omitempty"`
 }
 
 // validateCronExpression validates that a cron expression is valid.
 func validateCronExpression(expr string) error {
-	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	_, err := parser.Parse(expr)
+	_, err := models.ParseCron(expr)
 	return err
 }
 
@@ -84,13 +134,53 @@ func CreateFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 			return
 		}
 
+		// Validate the schedule fires, but not more often than allowed
+		if err := (&models.Frequency{Period: strings.TrimSpace(req.Period)}).ValidateFires(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency " + err.Error()})
+			return
+		}
+		if err := (&models.Frequency{Period: strings.TrimSpace(req.Period)}).ValidateInterval(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency " + err.Error()})
+			return
+		}
+
+		// Validate reset boundary, defaulting to the start of the next period
+		if req.ResetBoundary == "" {
+			req.ResetBoundary = models.ResetBoundaryStart
+		}
+		if !models.ValidResetBoundary(req.ResetBoundary) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reset boundary must be 'start' or 'end'"})
+			return
+		}
+
+		// Validate reset mode, defaulting to reusing the completed task
+		if req.ResetMode == "" {
+			req.ResetMode = models.ResetModeReuse
+		}
+		if !models.ValidResetMode(req.ResetMode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reset mode must be 'reuse' or 'clone'"})
+			return
+		}
+
+		// Validate reminder lead, where 0 means no reminders
+		if req.ReminderLead != nil && *req.ReminderLead < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reminder lead minutes must not be negative"})
+			return
+		}
+		if req.ReminderLead != nil && *req.ReminderLead == 0 {
+			req.ReminderLead = nil
+		}
+
 		frequency := models.Frequency{
-			Name:   strings.TrimSpace(req.Name),
-			Period: strings.TrimSpace(req.Period),
+			Name:          strings.TrimSpace(req.Name),
+			Period:        strings.TrimSpace(req.Period),
+			ResetBoundary: req.ResetBoundary,
+			ResetMode:     req.ResetMode,
+			ReminderLead:  req.ReminderLead,
 		}
 
 		if err := db.Create(&frequency).Error; err != nil {
-			if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "duplicate key") {
+			if isDuplicateKeyError(err) {
 				c.JSON(http.StatusConflict, gin.H{"error": "Frequency with this name already exists"})
 				return
 			}
@@ -114,8 +204,11 @@ func CreateFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 
 // UpdateFrequencyRequest represents the request payload for updating a frequency.
 type UpdateFrequencyRequest struct {
-	Name   *string `json:"name,omitempty"`
-	Period *string `json:"period,omitempty"`
+	Name          *string `json:"name,omitempty"`
+	Period        *string `json:"period,omitempty"`
+	ResetBoundary *string `json:"reset_boundary,omitempty"`
+	ResetMode     *string `json:"reset_mode,omitempty"`
+	ReminderLead  *int    `json:"reminder_lead_minutes,omitempty"`
 }
 
 // UpdateFrequency returns a handler function for updating an existing frequency.
@@ -145,6 +238,32 @@ func UpdateFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cron expression: " + err.Error()})
 				return
 			}
+			if err := (&models.Frequency{Period: strings.TrimSpace(*req.Period)}).ValidateFires(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency " + err.Error()})
+				return
+			}
+			if err := (&models.Frequency{Period: strings.TrimSpace(*req.Period)}).ValidateInterval(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency " + err.Error()})
+				return
+			}
+		}
+
+		// Validate reset boundary if provided
+		if req.ResetBoundary != nil && !models.ValidResetBoundary(*req.ResetBoundary) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reset boundary must be 'start' or 'end'"})
+			return
+		}
+
+		// Validate reset mode if provided
+		if req.ResetMode != nil && !models.ValidResetMode(*req.ResetMode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reset mode must be 'reuse' or 'clone'"})
+			return
+		}
+
+		// Validate reminder lead if provided, where 0 removes reminders
+		if req.ReminderLead != nil && *req.ReminderLead < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reminder lead minutes must not be negative"})
+			return
 		}
 
 		// Update fields
@@ -155,10 +274,23 @@ func UpdateFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 		if req.Period != nil {
 			updates["period"] = strings.TrimSpace(*req.Period)
 		}
+		if req.ResetBoundary != nil {
+			updates["reset_boundary"] = *req.ResetBoundary
+		}
+		if req.ResetMode != nil {
+			updates["reset_mode"] = *req.ResetMode
+		}
+		if req.ReminderLead != nil {
+			if *req.ReminderLead == 0 {
+				updates["reminder_lead_minutes"] = nil
+			} else {
+				updates["reminder_lead_minutes"] = *req.ReminderLead
+			}
+		}
 
 		if len(updates) > 0 {
 			if err := db.Model(&frequency).Updates(updates).Error; err != nil {
-				if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "duplicate key") {
+				if isDuplicateKeyError(err) {
 					c.JSON(http.StatusConflict, gin.H{"error": "Frequency with this name already exists"})
 					return
 				}
@@ -188,7 +320,55 @@ func UpdateFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 	}
 }
 
-// DeleteFrequency returns a handler function for deleting a frequency.
+// PauseFrequency returns a handler function that pauses a frequency, so the scheduler
+// stops resetting its completed tasks until it is resumed. Tasks keep their frequency.
+func PauseFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return setFrequencyEnabled(db, false, wsManager...)
+}
+
+// ResumeFrequency returns a handler function that resumes automatic resets of a paused frequency.
+func ResumeFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return setFrequencyEnabled(db, true, wsManager...)
+}
+
+// setFrequencyEnabled returns a handler function that enables or disables a frequency.
+func setFrequencyEnabled(db *gorm.DB, enabled bool, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		var frequency models.Frequency
+		if err := db.First(&frequency, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Frequency not found"})
+				return
+			}
+			log.Println("Error fetching frequency:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch frequency"})
+			return
+		}
+
+		if err := db.Model(&frequency).Update("enabled", enabled).Error; err != nil {
+			log.Println("Error updating frequency:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update frequency"})
+			return
+		}
+
+		// Broadcast WebSocket event
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("frequency_update", frequency)
+			}
+		}
+
+		c.JSON(http.StatusOK, frequency)
+	}
+}
+
+// DeleteFrequency returns a handler function for deleting a frequency. A frequency still
+// used by live tasks is only deleted with ?force=true, which removes it from those tasks;
+// otherwise the request fails with 409 and the number of affected tasks.
 func DeleteFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
@@ -204,6 +384,23 @@ func DeleteFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 			return
 		}
 
+		// Refuse to silently remove the frequency from live tasks unless forced
+		if force, _ := strconv.ParseBool(c.Query("force")); !force {
+			var count int64
+			if err := db.Model(&models.Task{}).Where("frequency_id = ? AND deleted = ?", id, false).Count(&count).Error; err != nil {
+				log.Println("Error counting frequency tasks:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete frequency"})
+				return
+			}
+			if count > 0 {
+				c.JSON(http.StatusConflict, gin.H{
+					"error":      fmt.Sprintf("Frequency is used by %d tasks; delete with ?force=true to remove it from them", count),
+					"task_count": count,
+				})
+				return
+			}
+		}
+
 		// Clear frequency_id from associated tasks
 		if err := db.Model(&models.Task{}).Where("frequency_id = ?", id).Update("frequency_id", nil).Error; err != nil {
 			log.Println("Error clearing frequency references from tasks:", err)
@@ -236,6 +433,239 @@ func DeleteFrequency(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 	}
 }
 
+// MoveFrequencyTasksRequest represents the request payload for moving tasks between frequencies.
+type MoveFrequencyTasksRequest struct {
+	FromID string `json:"from_id" binding:"required"`
+	ToID   string `json:"to_id" binding:"required"`
+}
+
+// MoveFrequencyTasksResult reports how many tasks were moved between frequencies.
+type MoveFrequencyTasksResult struct {
+	FromID string `json:"from_id"`
+	ToID   string `json:"to_id"`
+	Moved  int64  `json:"moved"`
+}
+
+// MoveFrequencyTasks returns a handler function that reassigns every live task of one
+// frequency to another in a single transaction. Tasks in the trash are left in place.
+func MoveFrequencyTasks(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req MoveFrequencyTasksRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if req.FromID == req.ToID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Source and target frequency must differ"})
+			return
+		}
+
+		var count int64
+		if err := db.Model(&models.Frequency{}).Where("id IN ?", []string{req.FromID, req.ToID}).Count(&count).Error; err != nil {
+			log.Println("Error validating frequencies:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate frequencies"})
+			return
+		}
+		if count != 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency not found"})
+			return
+		}
+
+		result := MoveFrequencyTasksResult{FromID: req.FromID, ToID: req.ToID}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			update := tx.Model(&models.Task{}).
+				Where("frequency_id = ? AND deleted = ?", req.FromID, false).
+				Update("frequency_id", req.ToID)
+			if update.Error != nil {
+				return update.Error
+			}
+			result.Moved = update.RowsAffected
+			return nil
+		})
+		if err != nil {
+			log.Println("Error moving frequency tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move tasks"})
+			return
+		}
+
+		// Broadcast a single WebSocket event for the whole batch
+		if result.Moved > 0 && len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_list_refresh", result)
+			}
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// CloneFrequencyTasksRequest represents the optional payload for cloning a frequency's tasks.
+// Copies stay on the source frequency unless a target is given.
+type CloneFrequencyTasksRequest struct {
+	TargetFrequencyID *string `json:"target_frequency_id,omitempty"`
+}
+
+// CloneFrequencyTasks returns a handler function that copies every incomplete task of a
+// frequency, including its tags, in a single transaction.
+func CloneFrequencyTasks(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var req CloneFrequencyTasksRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		var frequency models.Frequency
+		if err := db.First(&frequency, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Frequency not found"})
+				return
+			}
+			log.Println("Error fetching frequency:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch frequency"})
+			return
+		}
+
+		targetID := frequency.ID
+		if req.TargetFrequencyID != nil && *req.TargetFrequencyID != frequency.ID {
+			var target models.Frequency
+			if err := db.First(&target, "id = ?", *req.TargetFrequencyID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Target frequency not found"})
+					return
+				}
+				log.Println("Error validating frequency:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate frequency"})
+				return
+			}
+			targetID = target.ID
+		}
+
+		var sources []models.Task
+		if err := db.Preload("Tags").
+			Where("frequency_id = ? AND completed = ? AND deleted = ? AND archived = ?", frequency.ID, false, false, false).
+			Order("created_at").
+			Find(&sources).Error; err != nil {
+			log.Println("Error fetching tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+
+		created := make([]models.Task, 0, len(sources))
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, source := range sources {
+				task := copyTask(source)
+				task.FrequencyID = &targetID
+				task.Tags = source.Tags
+				if err := tx.Create(&task).Error; err != nil {
+					return err
+				}
+				created = append(created, task)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("Error cloning frequency tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone tasks"})
+			return
+		}
+		metrics.TasksCreated.Add(float64(len(created)))
+
+		// Reload with associations
+		for i := range created {
+			if err := db.Preload("Tags").Preload("Frequency").First(&created[i], "id = ?", created[i].ID).Error; err != nil {
+				log.Println("Error reloading task:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
+				return
+			}
+		}
+
+		// Broadcast a single WebSocket event for the whole batch
+		if len(created) > 0 && len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_list_refresh", created)
+			}
+		}
+
+		c.JSON(http.StatusCreated, created)
+	}
+}
+
+const (
+	// defaultFrequencyScheduleCount is the number of fire times previewed when no count is given.
+	defaultFrequencyScheduleCount = 5
+	// maxFrequencyScheduleCount is the largest number of fire times that can be previewed.
+	maxFrequencyScheduleCount = 100
+)
+
+// FrequencySchedule represents the upcoming fire times of a frequency.
+type FrequencySchedule struct {
+	FrequencyID string   `json:"frequency_id"`
+	Name        string   `json:"name"`
+	Period      string   `json:"period"`
+	FireTimes   []string `json:"fire_times"`
+}
+
+// GetFrequencySchedule returns a handler function that previews the next ?count= fire
+// times of a frequency as RFC3339 timestamps in the specified timezone.
+func GetFrequencySchedule(db *gorm.DB, location *time.Location, timezone string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count := defaultFrequencyScheduleCount
+		if value := c.Query("count"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > maxFrequencyScheduleCount {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Count must be between 1 and %d", maxFrequencyScheduleCount)})
+				return
+			}
+			count = parsed
+		}
+
+		id := c.Param("id")
+		var frequency models.Frequency
+		if err := db.First(&frequency, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Frequency not found"})
+				return
+			}
+			log.Println("Error fetching frequency:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch frequency"})
+			return
+		}
+
+		schedule, err := frequency.Schedule(timezone)
+		if err != nil {
+			log.Printf("Error parsing schedule for frequency %s: %v", frequency.Name, err)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Frequency has an invalid cron expression"})
+			return
+		}
+
+		response := FrequencySchedule{
+			FrequencyID: frequency.ID,
+			Name:        frequency.Name,
+			Period:      frequency.Period,
+			FireTimes:   make([]string, 0, count),
+		}
+		next := time.Now().In(location)
+		for len(response.FireTimes) < count {
+			next = schedule.Next(next)
+			if next.IsZero() {
+				break
+			}
+			response.FireTimes = append(response.FireTimes, next.In(location).Format(time.RFC3339))
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
 // FrequencyTimer represents the response structure for the timers endpoint.
 type FrequencyTimer struct {
 	Name           string `json:"name"`
@@ -253,7 +683,7 @@ func GetFrequencyTimers(db *gorm.DB, location *time.Location, timezone string) g
 			return
 		}
 
-		var timers []FrequencyTimer
+		timers := []FrequencyTimer{}
 		for _, freq := range frequencies {
 			timeUntilReset, err := freq.TimeUntilNextReset(location, timezone)
 			if err != nil {
@@ -270,3 +700,76 @@ func GetFrequencyTimers(db *gorm.DB, location *time.Location, timezone string) g
 		c.JSON(http.StatusOK, timers)
 	}
 }
+
+// FrequencySummary represents a frequency with aggregate counts of its active tasks.
+// DueNow counts incomplete tasks due by the end of today.
+type FrequencySummary struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Period     string `json:"period"`
+	Total      int64  `json:"total"`
+	Completed  int64  `json:"completed"`
+	Incomplete int64  `json:"incomplete"`
+	DueNow     int64  `json:"due_now"`
+}
+
+// frequencyTaskCounts holds the grouped task counts for a single frequency.
+type frequencyTaskCounts struct {
+	FrequencyID string
+	Total       int64
+	Completed   int64
+	DueNow      int64
+}
+
+// GetFrequencySummary returns a handler function for retrieving every frequency with
+// counts of its tasks by completion status, using the specified timezone to determine
+// the end of today.
+func GetFrequencySummary(db *gorm.DB, location *time.Location) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var frequencies []models.Frequency
+		if err := db.Order("name").Find(&frequencies).Error; err != nil {
+			log.Println("Error fetching frequencies:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch frequencies"})
+			return
+		}
+
+		now := time.Now().In(location)
+		endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location).AddDate(0, 0, 1)
+
+		var rows []frequencyTaskCounts
+		// Times are converted to local time to match how GORM stores them
+		if err := db.Model(&models.Task{}).
+			Select(`frequency_id, COUNT(*) AS total,
+				COALESCE(SUM(CASE WHEN completed = ? THEN 1 ELSE 0 END), 0) AS completed,
+				COALESCE(SUM(CASE WHEN completed = ? AND due_date < ? THEN 1 ELSE 0 END), 0) AS due_now`,
+				true, false, endOfDay.Local()).
+			Where("deleted = ? AND archived = ? AND frequency_id IS NOT NULL", false, false).
+			Group("frequency_id").
+			Scan(&rows).Error; err != nil {
+			log.Println("Error counting frequency tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count frequency tasks"})
+			return
+		}
+
+		counts := make(map[string]frequencyTaskCounts, len(rows))
+		for _, row := range rows {
+			counts[row.FrequencyID] = row
+		}
+
+		summaries := make([]FrequencySummary, 0, len(frequencies))
+		for _, freq := range frequencies {
+			row := counts[freq.ID]
+			summaries = append(summaries, FrequencySummary{
+				ID:         freq.ID,
+				Name:       freq.Name,
+				Period:     freq.Period,
+				Total:      row.Total,
+				Completed:  row.Completed,
+				Incomplete: row.Total - row.Completed,
+				DueNow:     row.DueNow,
+			})
+		}
+
+		c.JSON(http.StatusOK, summaries)
+	}
+}
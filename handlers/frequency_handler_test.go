@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jhoffmann/dailies/models"
@@ -17,7 +18,7 @@ func TestGetFrequencies(t *testing.T) {
 	db := setupTestHandlerDB(t)
 
 	r := gin.New()
-	r.GET("/frequencies", GetFrequencies(db))
+	r.GET("/frequencies", GetFrequencies(db, time.UTC, "UTC"))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/frequencies", nil)
@@ -34,6 +35,49 @@ func TestGetFrequencies(t *testing.T) {
 	}
 }
 
+func TestGetFrequenciesNextReset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	location, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Fatalf("Failed to load timezone: %v", err)
+	}
+
+	db.Create(&models.Frequency{Name: "Daily", Period: "0 18 * * *"})
+	db.Create(&models.Frequency{Name: "Broken", Period: "not a cron"})
+
+	r := gin.New()
+	r.GET("/frequencies", GetFrequencies(db, location, "America/Denver"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequencies", nil)
+	r.ServeHTTP(w, req)
+
+	var frequencies []models.Frequency
+	if err := json.Unmarshal(w.Body.Bytes(), &frequencies); err != nil {
+		t.Fatalf("Expected valid JSON array, got error: %v", err)
+	}
+	if len(frequencies) != 2 {
+		t.Fatalf("Expected 2 frequencies, got %d", len(frequencies))
+	}
+
+	broken, daily := frequencies[0], frequencies[1]
+	if broken.NextFire != nil {
+		t.Errorf("Expected no next reset for an invalid expression, got %v", broken.NextFire)
+	}
+	if daily.NextFire == nil {
+		t.Fatal("Expected a next reset for a valid expression")
+	}
+	next := daily.NextFire.In(location)
+	if next.Hour() != 18 || next.Minute() != 0 || !next.After(time.Now()) || next.Sub(time.Now()) > 24*time.Hour {
+		t.Errorf("Expected the next 6:00 PM in Denver, got %v", next)
+	}
+	if _, offset := daily.NextFire.Zone(); offset == 0 {
+		t.Errorf("Expected the next reset in the configured timezone, got %v", daily.NextFire)
+	}
+}
+
 func TestGetFrequencyNotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupTestHandlerDB(t)
@@ -263,3 +307,518 @@ func TestDeleteFrequency(t *testing.T) {
 		t.Error("Expected frequency to be deleted, but it still exists")
 	}
 }
+
+func TestDeleteFrequencyInUse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+	task := models.Task{Name: "Stretch", FrequencyID: &frequency.ID}
+	db.Create(&task)
+	db.Create(&models.Task{Name: "Old", FrequencyID: &frequency.ID, Deleted: true})
+
+	r := gin.New()
+	r.GET("/frequencies/:id", GetFrequency(db))
+	r.DELETE("/frequencies/:id", DeleteFrequency(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequencies/"+frequency.ID, nil)
+	r.ServeHTTP(w, req)
+
+	var fetched models.Frequency
+	json.Unmarshal(w.Body.Bytes(), &fetched)
+	if fetched.TaskCount != 1 {
+		t.Errorf("Expected task count 1, got %d", fetched.TaskCount)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/frequencies/"+frequency.ID, nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+	var response map[string]any
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["task_count"] != float64(1) {
+		t.Errorf("Expected task_count 1 in response, got %v", response["task_count"])
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/frequencies/"+frequency.ID+"?force=true", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+	db.First(&task, "id = ?", task.ID)
+	if task.FrequencyID != nil {
+		t.Error("Expected forced delete to remove the frequency from its tasks")
+	}
+}
+
+func TestMoveFrequencyTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	daily := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	weekly := models.Frequency{Name: "Weekly", Period: "0 0 * * 0"}
+	db.Create(&daily)
+	db.Create(&weekly)
+
+	for _, name := range []string{"First", "Second", "Third"} {
+		db.Create(&models.Task{Name: name, FrequencyID: &daily.ID})
+	}
+	db.Create(&models.Task{Name: "Untouched"})
+	trashed := models.Task{Name: "Trashed", FrequencyID: &daily.ID, Deleted: true}
+	db.Create(&trashed)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/frequencies/move", MoveFrequencyTasks(db, broadcaster))
+
+	body := `{"from_id": "` + daily.ID + `", "to_id": "` + weekly.ID + `"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/frequencies/move", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result MoveFrequencyTasksResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Moved != 3 {
+		t.Errorf("Expected 3 tasks moved, got %d", result.Moved)
+	}
+
+	var remaining, moved int64
+	db.Model(&models.Task{}).Where("frequency_id = ? AND deleted = ?", daily.ID, false).Count(&remaining)
+	db.Model(&models.Task{}).Where("frequency_id = ?", weekly.ID).Count(&moved)
+	if remaining != 0 {
+		t.Errorf("Expected no live tasks left on the source frequency, got %d", remaining)
+	}
+	db.First(&trashed, "id = ?", trashed.ID)
+	if trashed.FrequencyID == nil || *trashed.FrequencyID != daily.ID {
+		t.Errorf("Expected the trashed task to stay on the source frequency, got %v", trashed.FrequencyID)
+	}
+	if moved != 3 {
+		t.Errorf("Expected 3 tasks on the target frequency, got %d", moved)
+	}
+
+	if len(broadcaster.events) != 1 {
+		t.Errorf("Expected a single broadcast, got %d", len(broadcaster.events))
+	}
+}
+
+func TestMoveFrequencyTasksInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	daily := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&daily)
+
+	r := gin.New()
+	r.POST("/frequencies/move", MoveFrequencyTasks(db))
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"Same frequency", `{"from_id": "` + daily.ID + `", "to_id": "` + daily.ID + `"}`},
+		{"Missing target", `{"from_id": "` + daily.ID + `", "to_id": "missing"}`},
+		{"Missing field", `{"from_id": "` + daily.ID + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/frequencies/move", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestCreateFrequencyResetBoundary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/frequencies", CreateFrequency(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/frequencies", bytes.NewBufferString(`{"name": "Daily", "period": "0 0 * * *"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var frequency models.Frequency
+	json.Unmarshal(w.Body.Bytes(), &frequency)
+	if frequency.ResetBoundary != models.ResetBoundaryStart {
+		t.Errorf("Expected default reset boundary 'start', got '%s'", frequency.ResetBoundary)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/frequencies", bytes.NewBufferString(`{"name": "Weekly", "period": "0 0 * * 1", "reset_boundary": "middle"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid boundary, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestCreateFrequencyResetMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/frequencies", CreateFrequency(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/frequencies", bytes.NewBufferString(`{"name": "Daily", "period": "0 0 * * *"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var frequency models.Frequency
+	json.Unmarshal(w.Body.Bytes(), &frequency)
+	if frequency.ResetMode != models.ResetModeReuse {
+		t.Errorf("Expected default reset mode 'reuse', got '%s'", frequency.ResetMode)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/frequencies", bytes.NewBufferString(`{"name": "Weekly", "period": "0 0 * * 1", "reset_mode": "clone"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &frequency)
+	if w.Code != http.StatusCreated || frequency.ResetMode != models.ResetModeClone {
+		t.Errorf("Expected a frequency in clone mode, got status %d and mode '%s'", w.Code, frequency.ResetMode)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/frequencies", bytes.NewBufferString(`{"name": "Monthly", "period": "0 0 1 * *", "reset_mode": "replace"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid mode, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestFrequencyReminderLead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/frequencies", CreateFrequency(db))
+	r.PUT("/frequencies/:id", UpdateFrequency(db))
+
+	send := func(method, path, body string) (*httptest.ResponseRecorder, models.Frequency) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		var frequency models.Frequency
+		json.Unmarshal(w.Body.Bytes(), &frequency)
+		return w, frequency
+	}
+
+	w, frequency := send("POST", "/frequencies", `{"name": "Daily", "period": "0 18 * * *", "reminder_lead_minutes": 30}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if frequency.ReminderLead == nil || *frequency.ReminderLead != 30 {
+		t.Errorf("Expected a 30 minute reminder lead, got %v", frequency.ReminderLead)
+	}
+
+	if w, _ := send("PUT", "/frequencies/"+frequency.ID, `{"reminder_lead_minutes": -5}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a negative lead, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// Zero removes reminders
+	w, frequency = send("PUT", "/frequencies/"+frequency.ID, `{"reminder_lead_minutes": 0}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if frequency.ReminderLead != nil {
+		t.Errorf("Expected the reminder lead to be removed, got %d", *frequency.ReminderLead)
+	}
+}
+
+func TestCreateFrequencyMinimumInterval(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	models.SetMinFrequencyInterval(5 * time.Minute)
+	t.Cleanup(func() { models.SetMinFrequencyInterval(0) })
+
+	r := gin.New()
+	r.POST("/frequencies", CreateFrequency(db))
+
+	for body, expected := range map[string]int{
+		`{"name": "Every minute", "period": "* * * * *"}`: http.StatusBadRequest,
+		`{"name": "Daily", "period": "0 0 * * *"}`:        http.StatusCreated,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/frequencies", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != expected {
+			t.Errorf("Expected status %d for %s, got %d", expected, body, w.Code)
+		}
+	}
+}
+
+func TestFrequencyNeverFires(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+
+	r := gin.New()
+	r.POST("/frequencies", CreateFrequency(db))
+	r.PUT("/frequencies/:id", UpdateFrequency(db))
+
+	for _, tt := range []struct{ method, path, body string }{
+		{"POST", "/frequencies", `{"name": "February 30th", "period": "0 0 30 2 *"}`},
+		{"PUT", "/frequencies/" + frequency.ID, `{"period": "0 0 31 4 *"}`},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, tt.body, w.Code)
+		}
+	}
+}
+
+func TestCloneFrequencyTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	source := models.Frequency{Name: "Weekday", Period: "0 6 * * 1-5"}
+	target := models.Frequency{Name: "Weekend", Period: "0 8 * * 0,6"}
+	db.Create(&source)
+	db.Create(&target)
+
+	tag := models.Tag{Name: "routine", Color: "#336699"}
+	db.Create(&tag)
+	db.Create(&models.Task{Name: "Make coffee", FrequencyID: &source.ID, Tags: []models.Tag{tag}})
+	db.Create(&models.Task{Name: "Walk dog", FrequencyID: &source.ID})
+	db.Create(&models.Task{Name: "Already done", FrequencyID: &source.ID, Completed: true})
+
+	r := gin.New()
+	r.POST("/frequencies/:id/clone-tasks", CloneFrequencyTasks(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/frequencies/"+source.ID+"/clone-tasks", bytes.NewBufferString(`{"target_frequency_id": "`+target.ID+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var created []models.Task
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if len(created) != 2 {
+		t.Fatalf("Expected 2 cloned tasks, got %d", len(created))
+	}
+	if len(created[0].Tags) != 1 || created[0].Tags[0].ID != tag.ID {
+		t.Errorf("Expected tags to be cloned, got %+v", created[0].Tags)
+	}
+
+	var onSource, onTarget int64
+	db.Model(&models.Task{}).Where("frequency_id = ?", source.ID).Count(&onSource)
+	db.Model(&models.Task{}).Where("frequency_id = ?", target.ID).Count(&onTarget)
+	if onSource != 3 {
+		t.Errorf("Expected the 3 originals to remain on the source frequency, got %d", onSource)
+	}
+	if onTarget != 2 {
+		t.Errorf("Expected 2 tasks on the target frequency, got %d", onTarget)
+	}
+}
+
+func TestGetFrequencySummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	daily := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	weekly := models.Frequency{Name: "Weekly", Period: "0 0 * * 0"}
+	db.Create(&daily)
+	db.Create(&weekly)
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().AddDate(0, 0, 3)
+	db.Create(&models.Task{Name: "Done", FrequencyID: &daily.ID, Completed: true})
+	db.Create(&models.Task{Name: "Due", FrequencyID: &daily.ID, DueDate: &past})
+	db.Create(&models.Task{Name: "Later", FrequencyID: &daily.ID, DueDate: &future})
+
+	r := gin.New()
+	r.GET("/frequencies/summary", GetFrequencySummary(db, time.Local))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequencies/summary", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var summaries []FrequencySummary
+	json.Unmarshal(w.Body.Bytes(), &summaries)
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 summaries, got %d", len(summaries))
+	}
+
+	got := summaries[0]
+	if got.Name != "Daily" || got.Total != 3 || got.Completed != 1 || got.Incomplete != 2 || got.DueNow != 1 {
+		t.Errorf("Unexpected summary for Daily: %+v", got)
+	}
+	if summaries[1].Total != 0 {
+		t.Errorf("Expected Weekly to have no tasks, got %+v", summaries[1])
+	}
+}
+
+func TestGetFrequencySchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Evening", Period: "0 18 * * *"}
+	db.Create(&frequency)
+
+	r := gin.New()
+	r.GET("/frequencies/:id/schedule", GetFrequencySchedule(db, time.UTC, "UTC"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequencies/"+frequency.ID+"/schedule?count=3", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var schedule FrequencySchedule
+	json.Unmarshal(w.Body.Bytes(), &schedule)
+	if len(schedule.FireTimes) != 3 {
+		t.Fatalf("Expected 3 fire times, got %d", len(schedule.FireTimes))
+	}
+
+	var previous time.Time
+	for i, value := range schedule.FireTimes {
+		fire, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("Expected RFC3339 timestamp, got %q", value)
+		}
+		if fire.Hour() != 18 || fire.Minute() != 0 {
+			t.Errorf("Expected fire time at 18:00, got %s", value)
+		}
+		if i > 0 && fire.Sub(previous) != 24*time.Hour {
+			t.Errorf("Expected fire times a day apart, got %s and %s", previous, fire)
+		}
+		previous = fire
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/frequencies/"+frequency.ID+"/schedule?count=0", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid count, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetFrequencyByName(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	db.Create(&models.Frequency{Name: "Daily", Period: "0 0 * * *"})
+	db.Create(&models.Frequency{Name: "Daily Evening", Period: "0 18 * * *"})
+
+	r := gin.New()
+	r.GET("/frequencies/by-name/:name", GetFrequencyByName(db))
+
+	tests := []struct {
+		name           string
+		expectedStatus int
+	}{
+		{"Daily", http.StatusOK},
+		{"daily", http.StatusOK},
+		{"Dai", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/frequencies/by-name/"+tt.name, nil)
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.expectedStatus {
+			t.Errorf("Expected status %d for %q, got %d", tt.expectedStatus, tt.name, w.Code)
+			continue
+		}
+		if w.Code == http.StatusOK {
+			var frequency models.Frequency
+			json.Unmarshal(w.Body.Bytes(), &frequency)
+			if frequency.Name != "Daily" {
+				t.Errorf("Expected exact match Daily for %q, got %s", tt.name, frequency.Name)
+			}
+		}
+	}
+}
+
+func TestPauseResumeFrequency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/frequencies/:id/pause", PauseFrequency(db, broadcaster))
+	r.POST("/frequencies/:id/resume", ResumeFrequency(db, broadcaster))
+
+	for _, tt := range []struct {
+		action  string
+		enabled bool
+	}{
+		{"pause", false},
+		{"resume", true},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/frequencies/"+frequency.ID+"/"+tt.action, nil)
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d for %s, got %d", http.StatusOK, tt.action, w.Code)
+		}
+
+		var stored models.Frequency
+		db.First(&stored, "id = ?", frequency.ID)
+		if stored.Enabled != tt.enabled {
+			t.Errorf("Expected enabled %v after %s, got %v", tt.enabled, tt.action, stored.Enabled)
+		}
+	}
+
+	if len(broadcaster.events) != 2 || broadcaster.events[0] != "frequency_update" {
+		t.Errorf("Expected frequency_update broadcasts, got %v", broadcaster.events)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/frequencies/missing/pause", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown frequency, got %d", http.StatusNotFound, w.Code)
+	}
+}
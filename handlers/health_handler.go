@@ -9,29 +9,73 @@ import (
 	"gorm.io/gorm"
 )
 
-// GetHealth returns a Gin handler function that checks the health of the service
-// and database connection. It returns HTTP 200 if healthy, HTTP 503 if the database
-// connection is not active.
-func GetHealth(db *gorm.DB) gin.HandlerFunc {
+// HealthComponents holds the subsystems reported by GetHealth besides the database.
+// Components left nil are omitted from the report.
+type HealthComponents struct {
+	Config    *config.AppConfig
+	Scheduler interface{ Running() bool }
+	WebSocket interface{ ClientCount() int }
+}
+
+// GetHealth returns a Gin handler function that checks the health of the service. The
+// response has a top-level status and a components map with the status and details of
+// the database, the scheduler and the WebSocket server. It returns HTTP 200 if every
+// component is healthy, HTTP 503 if the database connection is not active or the
+// scheduler is not running.
+func GetHealth(db *gorm.DB, components HealthComponents) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		sqlDB, err := db.DB()
-		if err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"status":  "error",
-				"message": "Failed to get database connection",
-			})
-			return
+		healthy := true
+		report := gin.H{}
+
+		database := gin.H{"status": "ok"}
+		if cfg := components.Config; cfg != nil {
+			database["driver"] = cfg.DBDriver
+			// Connection strings of other drivers may hold credentials
+			if cfg.DBDriver == "sqlite" {
+				database["path"] = cfg.DBPath
+			}
+		}
+		message := ""
+		if sqlDB, err := db.DB(); err != nil {
+			message = "Failed to get database connection"
+		} else if err := sqlDB.Ping(); err != nil {
+			message = "Database connection is not active"
+		}
+		if message != "" {
+			healthy = false
+			database["status"] = "error"
+			database["message"] = message
+		}
+		report["database"] = database
+
+		if components.Scheduler != nil {
+			scheduler := gin.H{"status": "ok", "running": components.Scheduler.Running()}
+			if components.Config != nil {
+				scheduler["timezone"] = components.Config.Timezone
+			}
+			if !components.Scheduler.Running() {
+				healthy = false
+				scheduler["status"] = "error"
+			}
+			report["scheduler"] = scheduler
+		}
+
+		if components.WebSocket != nil {
+			report["websocket"] = gin.H{"status": "ok", "clients": components.WebSocket.ClientCount()}
 		}
 
-		if err := sqlDB.Ping(); err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"status":  "error",
-				"message": "Database connection is not active",
-			})
+		if !healthy {
+			response := gin.H{"status": "error", "components": report}
+			if message != "" {
+				response["message"] = message
+			} else {
+				response["message"] = "Scheduler is not running"
+			}
+			c.JSON(http.StatusServiceUnavailable, response)
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "components": report})
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/config"
 	"github.com/jhoffmann/dailies/models"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -22,13 +23,13 @@ func TestGetHealth_Success(t *testing.T) {
 	}
 
 	// Migrate to ensure database is properly set up
-	err = db.AutoMigrate(&models.Task{}, &models.Tag{}, &models.Frequency{})
+	err = db.AutoMigrate(&models.Task{}, &models.Tag{}, &models.Frequency{}, &models.TaskNote{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
 
 	r := gin.New()
-	r.GET("/health", GetHealth(db))
+	r.GET("/health", GetHealth(db, HealthComponents{}))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/health", nil)
@@ -68,7 +69,7 @@ func TestGetHealth_DatabaseConnectionFailure(t *testing.T) {
 	sqlDB.Close()
 
 	r := gin.New()
-	r.GET("/health", GetHealth(db))
+	r.GET("/health", GetHealth(db, HealthComponents{}))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/health", nil)
@@ -115,7 +116,7 @@ func TestGetHealth_InvalidDatabase(t *testing.T) {
 	}
 
 	r := gin.New()
-	r.GET("/health", GetHealth(db))
+	r.GET("/health", GetHealth(db, HealthComponents{}))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/health", nil)
@@ -165,3 +166,68 @@ func TestGetHealth_InvalidDatabase(t *testing.T) {
 		t.Errorf("Expected one of %v, got '%v'", expectedMessages, messageStr)
 	}
 }
+
+// fakeScheduler reports a fixed running state to GetHealth.
+type fakeScheduler struct{ running bool }
+
+// Running reports the fixed running state.
+func (s fakeScheduler) Running() bool { return s.running }
+
+// fakeWebSocket reports a fixed client count to GetHealth.
+type fakeWebSocket struct{ clients int }
+
+// ClientCount reports the fixed client count.
+func (ws fakeWebSocket) ClientCount() int { return ws.clients }
+
+func TestGetHealth_Components(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	cfg := &config.AppConfig{DBDriver: "sqlite", DBPath: "./tasks.db", Timezone: "America/Denver"}
+
+	check := func(scheduler fakeScheduler) (int, map[string]any) {
+		r := gin.New()
+		r.GET("/health", GetHealth(db, HealthComponents{Config: cfg, Scheduler: scheduler, WebSocket: fakeWebSocket{clients: 3}}))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/health", nil)
+		r.ServeHTTP(w, req)
+
+		var response map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return w.Code, response
+	}
+
+	code, response := check(fakeScheduler{running: true})
+	if code != http.StatusOK || response["status"] != "ok" {
+		t.Fatalf("Expected a healthy report, got %d: %v", code, response)
+	}
+	components := response["components"].(map[string]any)
+	database := components["database"].(map[string]any)
+	if database["status"] != "ok" || database["path"] != "./tasks.db" {
+		t.Errorf("Unexpected database component: %v", database)
+	}
+	scheduler := components["scheduler"].(map[string]any)
+	if scheduler["running"] != true || scheduler["timezone"] != "America/Denver" {
+		t.Errorf("Unexpected scheduler component: %v", scheduler)
+	}
+	websocket := components["websocket"].(map[string]any)
+	if websocket["clients"] != float64(3) {
+		t.Errorf("Unexpected websocket component: %v", websocket)
+	}
+
+	// A stopped scheduler makes the service unhealthy
+	code, response = check(fakeScheduler{running: false})
+	if code != http.StatusServiceUnavailable || response["status"] != "error" {
+		t.Fatalf("Expected an unhealthy report, got %d: %v", code, response)
+	}
+	scheduler = response["components"].(map[string]any)["scheduler"].(map[string]any)
+	if scheduler["status"] != "error" {
+		t.Errorf("Expected the scheduler component to report an error, got %v", scheduler)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
+)
+
+// GetTaskHistory returns a handler function for listing the scheduler resets of a task,
+// newest first.
+func GetTaskHistory(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
+		}
+
+		var task models.Task
+		if err := db.Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+				return
+			}
+			log.Println("Error fetching task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+
+		events := []models.TaskResetEvent{}
+		if err := db.Where("task_id = ?", task.ID).Order("reset_at DESC").Find(&events).Error; err != nil {
+			log.Println("Error fetching reset history:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reset history"})
+			return
+		}
+
+		c.JSON(http.StatusOK, events)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+)
+
+func TestGetTaskHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+	task := models.Task{Name: "Stretch", FrequencyID: &frequency.ID}
+	other := models.Task{Name: "Read"}
+	db.Create(&task)
+	db.Create(&other)
+
+	now := time.Now()
+	db.Create(&models.TaskResetEvent{TaskID: task.ID, FrequencyID: frequency.ID, ResetAt: now.Add(-48 * time.Hour)})
+	db.Create(&models.TaskResetEvent{TaskID: task.ID, FrequencyID: frequency.ID, ResetAt: now.Add(-24 * time.Hour)})
+	db.Create(&models.TaskResetEvent{TaskID: other.ID, FrequencyID: frequency.ID, ResetAt: now})
+
+	r := gin.New()
+	r.GET("/tasks/:id/history", GetTaskHistory(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks/"+task.ID+"/history", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var events []models.TaskResetEvent
+	if err := json.Unmarshal(w.Body.Bytes(), &events); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 reset events, got %d", len(events))
+	}
+	if !events[0].ResetAt.After(events[1].ResetAt) {
+		t.Error("Expected reset events newest first")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks/00000000-0000-4000-8000-000000000000/history", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown task, got %d", http.StatusNotFound, w.Code)
+	}
+}
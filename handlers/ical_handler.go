@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
+)
+
+// icalTimeFormat is the iCalendar UTC date-time format.
+const icalTimeFormat = "20060102T150405Z"
+
+// icalWeekdays maps cron day-of-week numbers to iCalendar BYDAY codes.
+var icalWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ExportTasksICS returns a handler function that renders all active tasks as an iCalendar
+// feed. Tasks whose frequency maps onto a daily, weekly, monthly or yearly rule become
+// recurring events starting at the next reset in the specified timezone; other tasks with
+// a due date become one-off events. Times are written in UTC.
+func ExportTasksICS(db *gorm.DB, location *time.Location, timezone string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tasks []models.Task
+		if err := db.Preload("Frequency").
+			Where("deleted = ? AND archived = ?", false, false).
+			Order("name").
+			Find(&tasks).Error; err != nil {
+			log.Println("Error fetching tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+
+		now := time.Now().In(location)
+		var b strings.Builder
+		writeICalLine(&b, "BEGIN:VCALENDAR")
+		writeICalLine(&b, "VERSION:2.0")
+		writeICalLine(&b, "PRODID:-//dailies//tasks//EN")
+		writeICalLine(&b, "CALSCALE:GREGORIAN")
+
+		for _, task := range tasks {
+			start, rule, ok := recurringEvent(task, now, timezone)
+			if !ok {
+				if task.DueDate == nil {
+					continue
+				}
+				start, rule = *task.DueDate, ""
+			}
+
+			writeICalLine(&b, "BEGIN:VEVENT")
+			writeICalLine(&b, "UID:"+task.ID+"@dailies")
+			writeICalLine(&b, "DTSTAMP:"+now.UTC().Format(icalTimeFormat))
+			writeICalLine(&b, "DTSTART:"+start.UTC().Format(icalTimeFormat))
+			if task.EstimatedMinutes != nil && *task.EstimatedMinutes > 0 {
+				writeICalLine(&b, fmt.Sprintf("DURATION:PT%dM", *task.EstimatedMinutes))
+			}
+			if rule != "" {
+				writeICalLine(&b, "RRULE:"+rule)
+			}
+			writeICalLine(&b, "SUMMARY:"+escapeICalText(task.Name))
+			if task.Description != nil && *task.Description != "" {
+				writeICalLine(&b, "DESCRIPTION:"+escapeICalText(*task.Description))
+			}
+			writeICalLine(&b, "END:VEVENT")
+		}
+
+		writeICalLine(&b, "END:VCALENDAR")
+
+		c.Header("Content-Disposition", `attachment; filename="dailies.ics"`)
+		c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(b.String()))
+	}
+}
+
+// recurringEvent returns the first occurrence and recurrence rule of a task's frequency.
+// It reports false when the task has no frequency or its cron expression has no
+// equivalent recurrence rule.
+func recurringEvent(task models.Task, now time.Time, timezone string) (time.Time, string, bool) {
+	if task.Frequency == nil {
+		return time.Time{}, "", false
+	}
+
+	rule, ok := cronToRRule(task.Frequency.Period)
+	if !ok {
+		return time.Time{}, "", false
+	}
+
+	schedule, err := task.Frequency.Schedule(timezone)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	start := schedule.Next(now)
+	if start.IsZero() {
+		return time.Time{}, "", false
+	}
+
+	if task.RecurUntil != nil {
+		rule += ";UNTIL=" + task.RecurUntil.UTC().Format(icalTimeFormat)
+	}
+	return start, rule, true
+}
+
+// cronToRRule translates the common daily, weekly, monthly and yearly cron patterns into
+// an iCalendar recurrence rule. The time of day is carried by the event start.
+func cronToRRule(expr string) (string, bool) {
+	switch expr = strings.TrimSpace(expr); expr {
+	case "@daily", "@midnight":
+		return "FREQ=DAILY", true
+	case "@weekly":
+		return "FREQ=WEEKLY;BYDAY=SU", true
+	case "@monthly":
+		return "FREQ=MONTHLY;BYMONTHDAY=1", true
+	case "@yearly", "@annually":
+		return "FREQ=YEARLY;BYMONTH=1;BYMONTHDAY=1", true
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return "", false
+	}
+	minute, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	// Only schedules firing once on each matching day can be expressed
+	if _, err := strconv.Atoi(minute); err != nil {
+		return "", false
+	}
+	if _, err := strconv.Atoi(hour); err != nil {
+		return "", false
+	}
+
+	switch {
+	case dom == "*" && month == "*" && dow == "*":
+		return "FREQ=DAILY", true
+	case dom == "*" && month == "*":
+		days, ok := icalByDay(dow)
+		if !ok {
+			return "", false
+		}
+		return "FREQ=WEEKLY;BYDAY=" + days, true
+	case month == "*" && dow == "*":
+		day, err := strconv.Atoi(dom)
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("FREQ=MONTHLY;BYMONTHDAY=%d", day), true
+	case dow == "*":
+		day, dayErr := strconv.Atoi(dom)
+		m, monthErr := strconv.Atoi(month)
+		if dayErr != nil || monthErr != nil {
+			return "", false
+		}
+		return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYMONTHDAY=%d", m, day), true
+	}
+	return "", false
+}
+
+// icalByDay converts a cron day-of-week field of single days, lists and ranges into a
+// comma separated list of iCalendar BYDAY codes.
+func icalByDay(dow string) (string, bool) {
+	var codes []string
+	for _, part := range strings.Split(dow, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := cronWeekday(from)
+		if !ok {
+			return "", false
+		}
+		last := first
+		if isRange {
+			if last, ok = cronWeekday(to); !ok || last < first {
+				return "", false
+			}
+		}
+		for day := first; day <= last; day++ {
+			codes = append(codes, icalWeekdays[day])
+		}
+	}
+	return strings.Join(codes, ","), true
+}
+
+// cronWeekday parses a numeric or three-letter cron day of the week.
+func cronWeekday(value string) (int, bool) {
+	if day, err := strconv.Atoi(value); err == nil {
+		return day, day >= 0 && day < len(icalWeekdays)
+	}
+	for day, name := range []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"} {
+		if strings.EqualFold(value, name) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// escapeICalText escapes a value for use in an iCalendar TEXT property.
+func escapeICalText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// writeICalLine writes a content line terminated by CRLF, folding it so that no line
+// exceeds 75 octets without splitting a UTF-8 sequence.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, leaving 74 octets for content
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
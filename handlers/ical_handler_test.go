@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+)
+
+func TestExportTasksICS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	daily := models.Frequency{Name: "Daily", Period: "0 18 * * *"}
+	weekdays := models.Frequency{Name: "Weekdays", Period: "30 7 * * 1-5"}
+	hourly := models.Frequency{Name: "Quarterly Hour", Period: "*/15 * * * *"}
+	db.Create(&daily)
+	db.Create(&weekdays)
+	db.Create(&hourly)
+
+	due := time.Date(2030, 1, 2, 15, 0, 0, 0, time.UTC)
+	db.Create(&models.Task{Name: "Water plants, daily", FrequencyID: &daily.ID})
+	db.Create(&models.Task{Name: "Stand-up", FrequencyID: &weekdays.ID})
+	db.Create(&models.Task{Name: "Check queue", FrequencyID: &hourly.ID})
+	db.Create(&models.Task{Name: "File taxes", DueDate: &due})
+
+	r := gin.New()
+	r.GET("/tasks.ics", ExportTasksICS(db, time.UTC, "UTC"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks.ics", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/calendar") {
+		t.Errorf("Expected text/calendar content type, got %s", contentType)
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.Contains(disposition, `filename="dailies.ics"`) {
+		t.Errorf("Expected a filename in Content-Disposition, got %s", disposition)
+	}
+
+	body := w.Body.String()
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"SUMMARY:Water plants\\, daily\r\n",
+		"RRULE:FREQ=DAILY\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR\r\n",
+		"DTSTART:20300102T150000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected calendar to contain %q, got:\n%s", expected, body)
+		}
+	}
+
+	// Schedules without an equivalent recurrence rule and no due date are skipped
+	if strings.Contains(body, "Check queue") {
+		t.Error("Expected task with an untranslatable schedule to be skipped")
+	}
+	if events := strings.Count(body, "BEGIN:VEVENT"); events != 3 {
+		t.Errorf("Expected 3 events, got %d", events)
+	}
+}
+
+func TestWriteICalLineFolds(t *testing.T) {
+	var b strings.Builder
+	writeICalLine(&b, "SUMMARY:"+strings.Repeat("a", 200))
+
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Expected folded lines of at most 75 octets, got %d", len(line))
+		}
+	}
+	if unfolded := strings.ReplaceAll(b.String(), "\r\n ", ""); unfolded != "SUMMARY:"+strings.Repeat("a", 200)+"\r\n" {
+		t.Errorf("Expected unfolding to restore the line, got %q", unfolded)
+	}
+}
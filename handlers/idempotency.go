@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// idempotencyKeyHeader is the request header carrying a client-supplied idempotency key.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyKeyTTL is how long a key replays the task it created.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength is the longest idempotency key accepted.
+const maxIdempotencyKeyLength = 255
+
+// idempotencySweepInterval is how often expired keys are discarded.
+const idempotencySweepInterval = time.Minute
+
+// idempotencyEntry holds the task created for a key. An empty task ID marks a request
+// that is still in progress.
+type idempotencyEntry struct {
+	taskID  string
+	expires time.Time
+}
+
+// idempotencyCache maps recent idempotency keys to the IDs of the tasks they created.
+type idempotencyCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+// newIdempotencyCache creates an empty cache whose keys expire after ttl.
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		ttl:       ttl,
+		entries:   make(map[string]*idempotencyEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// reserve claims a key for a new request, returning an empty ID. When the key was already
+// used it returns the ID of the task created for it instead, or reports false while that
+// request is still in progress. A claimed key must be completed or released.
+func (ic *idempotencyCache) reserve(key string, now time.Time) (string, bool) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	if now.Sub(ic.lastSweep) >= idempotencySweepInterval {
+		ic.sweep(now)
+	}
+
+	if entry, ok := ic.entries[key]; ok && now.Before(entry.expires) {
+		return entry.taskID, entry.taskID != ""
+	}
+	ic.entries[key] = &idempotencyEntry{expires: now.Add(ic.ttl)}
+	return "", true
+}
+
+// complete records the task created for a claimed key.
+func (ic *idempotencyCache) complete(key, taskID string, now time.Time) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	ic.entries[key] = &idempotencyEntry{taskID: taskID, expires: now.Add(ic.ttl)}
+}
+
+// release frees a claimed key after its request failed, so it can be retried.
+func (ic *idempotencyCache) release(key string) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	delete(ic.entries, key)
+}
+
+// sweep discards expired keys.
+func (ic *idempotencyCache) sweep(now time.Time) {
+	for key, entry := range ic.entries {
+		if !now.Before(entry.expires) {
+			delete(ic.entries, key)
+		}
+	}
+	ic.lastSweep = now
+}
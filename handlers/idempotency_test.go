@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdempotencyCache(t *testing.T) {
+	cache := newIdempotencyCache(time.Hour)
+	now := time.Now()
+
+	if taskID, ok := cache.reserve("key", now); !ok || taskID != "" {
+		t.Fatalf("Expected new key to be claimed, got %q, %v", taskID, ok)
+	}
+	if _, ok := cache.reserve("key", now); ok {
+		t.Error("Expected key in progress to be rejected")
+	}
+
+	cache.release("key")
+	if taskID, ok := cache.reserve("key", now); !ok || taskID != "" {
+		t.Fatalf("Expected released key to be claimed again, got %q, %v", taskID, ok)
+	}
+
+	cache.complete("key", "task-1", now)
+	if taskID, ok := cache.reserve("key", now.Add(30*time.Minute)); !ok || taskID != "task-1" {
+		t.Errorf("Expected completed key to replay task-1, got %q, %v", taskID, ok)
+	}
+
+	// Expired keys are claimed anew and swept
+	if taskID, ok := cache.reserve("key", now.Add(2*time.Hour)); !ok || taskID != "" {
+		t.Errorf("Expected expired key to be claimed again, got %q, %v", taskID, ok)
+	}
+	cache.complete("other", "task-2", now)
+	cache.reserve("new", now.Add(3*time.Hour))
+	if _, ok := cache.entries["other"]; ok {
+		t.Error("Expected expired key to be swept")
+	}
+}
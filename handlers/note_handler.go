@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
+)
+
+// attachNoteCounts fills in the note count of each task with a single query.
+func attachNoteCounts(db *gorm.DB, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var counts []struct {
+		TaskID string
+		Count  int64
+	}
+	if err := db.Model(&models.TaskNote{}).
+		Select("task_id, COUNT(*) AS count").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+
+	byTask := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		byTask[count.TaskID] = count.Count
+	}
+	for i := range tasks {
+		tasks[i].NoteCount = byTask[tasks[i].ID]
+	}
+	return nil
+}
+
+// GetTaskNotes returns a handler function for listing the notes of a task, newest first.
+func GetTaskNotes(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
+		}
+
+		var task models.Task
+		if err := db.Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+				return
+			}
+			log.Println("Error fetching task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+
+		notes := []models.TaskNote{}
+		if err := db.Where("task_id = ?", task.ID).Order("created_at DESC").Find(&notes).Error; err != nil {
+			log.Println("Error fetching notes:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notes"})
+			return
+		}
+
+		c.JSON(http.StatusOK, notes)
+	}
+}
+
+// CreateTaskNoteRequest represents the request payload for adding a note to a task.
+type CreateTaskNoteRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CreateTaskNote returns a handler function for appending a note to a task.
+func CreateTaskNote(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
+		}
+		var req CreateTaskNoteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		body := strings.TrimSpace(req.Body)
+		if body == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Note body must not be empty"})
+			return
+		}
+
+		var task models.Task
+		if err := db.Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+				return
+			}
+			log.Println("Error fetching task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+
+		note := models.TaskNote{TaskID: task.ID, Body: body}
+		if err := db.Create(&note).Error; err != nil {
+			log.Println("Error creating note:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create note"})
+			return
+		}
+
+		broadcastNoteChange(db, task.ID, wsManager...)
+
+		c.JSON(http.StatusCreated, note)
+	}
+}
+
+// DeleteNote returns a handler function for deleting a single task note.
+func DeleteNote(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		var note models.TaskNote
+		if err := db.First(&note, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+				return
+			}
+			log.Println("Error fetching note:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch note"})
+			return
+		}
+
+		if err := db.Delete(&note).Error; err != nil {
+			log.Println("Error deleting note:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete note"})
+			return
+		}
+
+		broadcastNoteChange(db, note.TaskID, wsManager...)
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// broadcastNoteChange broadcasts the task owning a changed note so clients see its new note count.
+func broadcastNoteChange(db *gorm.DB, taskID string, wsManager ...any) {
+	if len(wsManager) == 0 || wsManager[0] == nil {
+		return
+	}
+	ws, ok := wsManager[0].(interface {
+		Broadcast(eventType any, data any)
+	})
+	if !ok {
+		return
+	}
+
+	var tasks []models.Task
+	if err := db.Preload("Tags").Preload("Frequency").Where("id = ?", taskID).Find(&tasks).Error; err != nil || len(tasks) == 0 {
+		return
+	}
+	if err := attachNoteCounts(db, tasks); err != nil {
+		log.Println("Error counting notes:", err)
+	}
+	ws.Broadcast("task_update", tasks[0])
+}
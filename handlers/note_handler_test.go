@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+)
+
+func TestTaskNotes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	task := models.Task{Name: "Write thesis"}
+	db.Create(&task)
+
+	r := gin.New()
+	r.GET("/tasks/:id", GetTask(db))
+	r.GET("/tasks/:id/notes", GetTaskNotes(db))
+	r.POST("/tasks/:id/notes", CreateTaskNote(db))
+	r.DELETE("/notes/:id", DeleteNote(db))
+
+	for _, body := range []string{"Outlined chapter one", "Drafted introduction"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tasks/"+task.ID+"/notes", bytes.NewBufferString(`{"body": "`+body+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks/"+task.ID+"/notes", nil)
+	r.ServeHTTP(w, req)
+
+	var notes []models.TaskNote
+	json.Unmarshal(w.Body.Bytes(), &notes)
+	if len(notes) != 2 {
+		t.Fatalf("Expected 2 notes, got %d", len(notes))
+	}
+	if notes[0].Body != "Drafted introduction" || notes[1].Body != "Outlined chapter one" {
+		t.Errorf("Expected notes newest first, got '%s' then '%s'", notes[0].Body, notes[1].Body)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks/"+task.ID, nil)
+	r.ServeHTTP(w, req)
+
+	var fetched models.Task
+	json.Unmarshal(w.Body.Bytes(), &fetched)
+	if fetched.NoteCount != 2 {
+		t.Errorf("Expected note count 2 on the task, got %d", fetched.NoteCount)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/notes/"+notes[0].ID, nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+
+	var remaining int64
+	db.Model(&models.TaskNote{}).Where("task_id = ?", task.ID).Count(&remaining)
+	if remaining != 1 {
+		t.Errorf("Expected 1 note after deletion, got %d", remaining)
+	}
+}
+
+func TestCreateTaskNoteEmptyBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	task := models.Task{Name: "Write thesis"}
+	db.Create(&task)
+
+	r := gin.New()
+	r.POST("/tasks/:id/notes", CreateTaskNote(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks/"+task.ID+"/notes", bytes.NewBufferString(`{"body": "   "}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
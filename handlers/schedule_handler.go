@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultScheduleWindow is the schedule window length used when no end is given.
+	defaultScheduleWindow = 7 * 24 * time.Hour
+	// maxScheduleWindow is the longest schedule window that can be requested.
+	maxScheduleWindow = 31 * 24 * time.Hour
+)
+
+// ScheduleEvent represents a projected reset of a task by its frequency.
+type ScheduleEvent struct {
+	TaskID        string    `json:"task_id"`
+	TaskName      string    `json:"task_name"`
+	Time          time.Time `json:"time"`
+	FrequencyID   string    `json:"frequency_id"`
+	FrequencyName string    `json:"frequency_name"`
+}
+
+// GetSchedule returns a handler function that lists the projected reset events of all
+// tasks with an enabled frequency between ?from= and ?to= (RFC3339), in chronological order.
+// The window defaults to the next seven days and is capped at 31 days.
+func GetSchedule(db *gorm.DB, location *time.Location, timezone string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from := time.Now().In(location)
+		if value, err := parseTimeQuery(c, "from"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else if value != nil {
+			from = value.In(location)
+		}
+
+		to := from.Add(defaultScheduleWindow)
+		if value, err := parseTimeQuery(c, "to"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else if value != nil {
+			to = value.In(location)
+		}
+
+		if !to.After(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from"})
+			return
+		}
+		if to.Sub(from) > maxScheduleWindow {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Schedule window must not exceed 31 days"})
+			return
+		}
+
+		var tasks []models.Task
+		if err := db.Preload("Frequency").
+			Where("deleted = ? AND archived = ? AND frequency_id IS NOT NULL", false, false).
+			Find(&tasks).Error; err != nil {
+			log.Println("Error fetching tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+
+		// Compute the fire times once per frequency rather than once per task
+		fireTimes := make(map[string][]time.Time)
+		events := []ScheduleEvent{}
+		for _, task := range tasks {
+			// Paused frequencies do not reset their tasks
+			if task.Frequency == nil || !task.Frequency.Enabled {
+				continue
+			}
+
+			times, ok := fireTimes[task.Frequency.ID]
+			if !ok {
+				var err error
+				times, err = task.Frequency.FireTimes(timezone, from, to)
+				if err != nil {
+					log.Printf("Error calculating schedule for frequency %s: %v", task.Frequency.Name, err)
+				}
+				fireTimes[task.Frequency.ID] = times
+			}
+
+			for _, fireTime := range times {
+				if task.RecurUntil != nil && fireTime.After(*task.RecurUntil) {
+					break
+				}
+				events = append(events, ScheduleEvent{
+					TaskID:        task.ID,
+					TaskName:      task.Name,
+					Time:          fireTime.In(location),
+					FrequencyID:   task.Frequency.ID,
+					FrequencyName: task.Frequency.Name,
+				})
+			}
+		}
+
+		sort.SliceStable(events, func(i, j int) bool {
+			if !events[i].Time.Equal(events[j].Time) {
+				return events[i].Time.Before(events[j].Time)
+			}
+			return events[i].TaskName < events[j].TaskName
+		})
+
+		c.JSON(http.StatusOK, events)
+	}
+}
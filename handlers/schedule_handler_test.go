@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+)
+
+func TestGetSchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	daily := models.Frequency{Name: "Daily", Period: "0 9 * * *"}
+	paused := models.Frequency{Name: "Paused", Period: "0 10 * * *"}
+	db.Create(&daily)
+	db.Create(&paused)
+	db.Model(&paused).Update("enabled", false)
+
+	water := models.Task{Name: "Water plants", FrequencyID: &daily.ID}
+	stretch := models.Task{Name: "Stretch", FrequencyID: &daily.ID}
+	oneOff := models.Task{Name: "One-off"}
+	onHold := models.Task{Name: "On hold", FrequencyID: &paused.ID}
+	db.Create(&water)
+	db.Create(&stretch)
+	db.Create(&oneOff)
+	db.Create(&onHold)
+
+	r := gin.New()
+	r.GET("/schedule", GetSchedule(db, time.UTC, "UTC"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/schedule?from=2025-03-14T00:00:00Z&to=2025-03-16T00:00:00Z", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var events []ScheduleEvent
+	json.Unmarshal(w.Body.Bytes(), &events)
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events (one per task per day), got %d", len(events))
+	}
+
+	firstDay := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	secondDay := firstDay.AddDate(0, 0, 1)
+	expected := []struct {
+		name string
+		time time.Time
+	}{
+		{"Stretch", firstDay},
+		{"Water plants", firstDay},
+		{"Stretch", secondDay},
+		{"Water plants", secondDay},
+	}
+	for i, e := range expected {
+		if events[i].TaskName != e.name || !events[i].Time.Equal(e.time) {
+			t.Errorf("Event %d: expected %s at %v, got %s at %v", i, e.name, e.time, events[i].TaskName, events[i].Time)
+		}
+		if events[i].FrequencyName != "Daily" {
+			t.Errorf("Event %d: expected frequency 'Daily', got '%s'", i, events[i].FrequencyName)
+		}
+	}
+}
+
+func TestGetScheduleWindowTooLong(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.GET("/schedule", GetSchedule(db, time.UTC, "UTC"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/schedule?from=2025-01-01T00:00:00Z&to=2025-03-01T00:00:00Z", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
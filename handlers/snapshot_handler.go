@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
+)
+
+// snapshotBatchSize is the number of snapshot entries inserted per statement.
+const snapshotBatchSize = 500
+
+// CreateSnapshotRequest represents the request payload for creating a snapshot.
+type CreateSnapshotRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateSnapshot returns a handler function that records the current completion state
+// of every task under a named snapshot.
+func CreateSnapshot(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateSnapshotRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Snapshot name cannot be empty"})
+			return
+		}
+
+		snapshot := models.Snapshot{Name: name}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Omit("Tasks").Create(&snapshot).Error; err != nil {
+				return err
+			}
+
+			var tasks []models.Task
+			if err := tx.Select("id", "completed").Where("deleted = ?", false).Find(&tasks).Error; err != nil {
+				return err
+			}
+
+			snapshot.Tasks = make([]models.SnapshotTask, len(tasks))
+			for i, task := range tasks {
+				snapshot.Tasks[i] = models.SnapshotTask{SnapshotID: snapshot.ID, TaskID: task.ID, Completed: task.Completed}
+			}
+			if len(snapshot.Tasks) == 0 {
+				return nil
+			}
+			return tx.CreateInBatches(snapshot.Tasks, snapshotBatchSize).Error
+		})
+		if err != nil {
+			log.Println("Error creating snapshot:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create snapshot"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, snapshot)
+	}
+}
+
+// RestoreSnapshotResult reports how many tasks were restored from a snapshot.
+type RestoreSnapshotResult struct {
+	SnapshotID string `json:"snapshot_id"`
+	Restored   int64  `json:"restored"`
+}
+
+// RestoreSnapshot returns a handler function that reverts the completion state of tasks
+// to a snapshot, reporting how many tasks changed. Tasks already in their recorded state,
+// and tasks deleted since the snapshot was taken, are left untouched.
+func RestoreSnapshot(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		var snapshot models.Snapshot
+		if err := db.Preload("Tasks").First(&snapshot, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Snapshot not found"})
+				return
+			}
+			log.Println("Error fetching snapshot:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch snapshot"})
+			return
+		}
+
+		// Group task IDs by their recorded state so each state is restored in one statement
+		byState := map[bool][]string{}
+		for _, entry := range snapshot.Tasks {
+			byState[entry.Completed] = append(byState[entry.Completed], entry.TaskID)
+		}
+
+		result := RestoreSnapshotResult{SnapshotID: snapshot.ID}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for completed, ids := range byState {
+				update := tx.Model(&models.Task{}).
+					Where("id IN ? AND deleted = ? AND completed = ?", ids, false, !completed).
+					Update("completed", completed)
+				if update.Error != nil {
+					return update.Error
+				}
+				result.Restored += update.RowsAffected
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("Error restoring snapshot:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore snapshot"})
+			return
+		}
+
+		// Broadcast a single WebSocket event for the whole snapshot
+		if result.Restored > 0 && len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_list_refresh", result)
+			}
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
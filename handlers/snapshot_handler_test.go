@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+)
+
+func TestSnapshotAndRestore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	done := models.Task{Name: "Done", Completed: true}
+	open := models.Task{Name: "Open"}
+	removed := models.Task{Name: "Removed", Completed: true}
+	unchanged := models.Task{Name: "Unchanged", Completed: true}
+	db.Create(&done)
+	db.Create(&open)
+	db.Create(&removed)
+	db.Create(&unchanged)
+	completedAt := time.Now().Add(-time.Hour)
+	db.Model(&unchanged).UpdateColumn("updated_at", completedAt)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/snapshots", CreateSnapshot(db))
+	r.POST("/snapshots/:id/restore", RestoreSnapshot(db, broadcaster))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/snapshots", bytes.NewBufferString(`{"name": "Before reorg"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var snapshot models.Snapshot
+	json.Unmarshal(w.Body.Bytes(), &snapshot)
+	if len(snapshot.Tasks) != 4 {
+		t.Fatalf("Expected 4 tasks in snapshot, got %d", len(snapshot.Tasks))
+	}
+
+	// Toggle all but one task, then delete one so restoring must skip it
+	db.Model(&models.Task{}).Where("id = ?", done.ID).Update("completed", false)
+	db.Model(&models.Task{}).Where("id = ?", open.ID).Update("completed", true)
+	db.Model(&models.Task{}).Where("id = ?", removed.ID).Updates(map[string]any{"completed": false, "deleted": true})
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/snapshots/"+snapshot.ID+"/restore", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result RestoreSnapshotResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Restored != 2 {
+		t.Errorf("Expected 2 restored tasks, got %d", result.Restored)
+	}
+
+	expected := map[string]bool{done.ID: true, open.ID: false, removed.ID: false, unchanged.ID: true}
+	for id, completed := range expected {
+		var task models.Task
+		db.First(&task, "id = ?", id)
+		if task.Completed != completed {
+			t.Errorf("Expected task %s completed=%v, got %v", task.Name, completed, task.Completed)
+		}
+	}
+
+	var stored models.Task
+	db.First(&stored, "id = ?", unchanged.ID)
+	if !stored.UpdatedAt.Equal(completedAt) {
+		t.Errorf("Expected a task already in its recorded state to keep updated_at %v, got %v", completedAt, stored.UpdatedAt)
+	}
+
+	if len(broadcaster.events) != 1 {
+		t.Errorf("Expected a single broadcast, got %d", len(broadcaster.events))
+	}
+
+	// Restoring again changes nothing
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/snapshots/"+snapshot.ID+"/restore", nil)
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Restored != 0 {
+		t.Errorf("Expected no restored tasks on the second restore, got %d", result.Restored)
+	}
+	if len(broadcaster.events) != 1 {
+		t.Errorf("Expected no broadcast when nothing changed, got %d", len(broadcaster.events))
+	}
+}
+
+func TestRestoreSnapshotNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/snapshots/:id/restore", RestoreSnapshot(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/snapshots/missing/restore", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
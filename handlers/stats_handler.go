@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
+)
+
+// TagTaskCount represents the number of active tasks carrying a single tag.
+type TagTaskCount struct {
+	TagID string `json:"tag_id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// FrequencyTaskCount represents the number of active tasks on a single frequency.
+type FrequencyTaskCount struct {
+	FrequencyID string `json:"frequency_id"`
+	Name        string `json:"name"`
+	Count       int64  `json:"count"`
+}
+
+// TaskStats represents aggregate completion metrics for active tasks. The completion
+// rates are the share of active tasks completed within the last 7 and 30 days, based
+// on when each task was last updated. Deleted tasks and tags are only counted when
+// explicitly requested.
+type TaskStats struct {
+	Total            int64                `json:"total"`
+	Completed        int64                `json:"completed"`
+	Incomplete       int64                `json:"incomplete"`
+	CompletionRate7  float64              `json:"completion_rate_7d"`
+	CompletionRate30 float64              `json:"completion_rate_30d"`
+	ByTag            []TagTaskCount       `json:"by_tag"`
+	ByFrequency      []FrequencyTaskCount `json:"by_frequency"`
+}
+
+// taskTotals holds the aggregate counts used to build TaskStats.
+type taskTotals struct {
+	Total       int64
+	Completed   int64
+	Completed7  int64
+	Completed30 int64
+}
+
+// GetStats returns a handler function that reports task completion metrics, computed
+// with aggregate queries rather than by loading every task.
+func GetStats(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now()
+		includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
+
+		// Limit aggregates to unarchived tasks, and to live ones unless deleted are included
+		taskScope := func(tx *gorm.DB) *gorm.DB {
+			tx = tx.Where("tasks.archived = ?", false)
+			if !includeDeleted {
+				tx = tx.Where("tasks.deleted = ?", false)
+			}
+			return tx
+		}
+
+		var totals taskTotals
+		// Times are converted to local time to match how GORM stores them
+		if err := db.Model(&models.Task{}).
+			Select(`COUNT(*) AS total,
+				COALESCE(SUM(CASE WHEN completed = ? THEN 1 ELSE 0 END), 0) AS completed,
+				COALESCE(SUM(CASE WHEN completed = ? AND updated_at >= ? THEN 1 ELSE 0 END), 0) AS completed7,
+				COALESCE(SUM(CASE WHEN completed = ? AND updated_at >= ? THEN 1 ELSE 0 END), 0) AS completed30`,
+				true, true, now.AddDate(0, 0, -7).Local(), true, now.AddDate(0, 0, -30).Local()).
+			Scopes(taskScope).
+			Scan(&totals).Error; err != nil {
+			log.Println("Error counting tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+			return
+		}
+
+		stats := TaskStats{
+			Total:       totals.Total,
+			Completed:   totals.Completed,
+			Incomplete:  totals.Total - totals.Completed,
+			ByTag:       []TagTaskCount{},
+			ByFrequency: []FrequencyTaskCount{},
+		}
+		if totals.Total > 0 {
+			stats.CompletionRate7 = float64(totals.Completed7) / float64(totals.Total)
+			stats.CompletionRate30 = float64(totals.Completed30) / float64(totals.Total)
+		}
+
+		tagQuery := db.Table("task_tags").
+			Select("tags.id AS tag_id, tags.name, COUNT(*) AS count").
+			Joins("JOIN tasks ON tasks.id = task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Scopes(taskScope)
+		if !includeDeleted {
+			tagQuery = tagQuery.Where("tags.deleted_at IS NULL")
+		}
+		if err := tagQuery.
+			Group("tags.id, tags.name").
+			Order("count DESC, tags.name").
+			Scan(&stats.ByTag).Error; err != nil {
+			log.Println("Error counting tasks per tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+			return
+		}
+
+		if err := db.Model(&models.Task{}).
+			Select("frequencies.id AS frequency_id, frequencies.name, COUNT(*) AS count").
+			Joins("JOIN frequencies ON frequencies.id = tasks.frequency_id").
+			Scopes(taskScope).
+			Group("frequencies.id, frequencies.name").
+			Order("count DESC, frequencies.name").
+			Scan(&stats.ByFrequency).Error; err != nil {
+			log.Println("Error counting tasks per frequency:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+			return
+		}
+
+		c.JSON(http.StatusOK, stats)
+	}
+}
+
+// TagStats represents aggregate completion metrics for the active tasks carrying a
+// single tag. AveragePriority is omitted when none of those tasks has a priority.
+type TagStats struct {
+	TagID           string   `json:"tag_id"`
+	Name            string   `json:"name"`
+	Total           int64    `json:"total"`
+	Completed       int64    `json:"completed"`
+	Incomplete      int64    `json:"incomplete"`
+	CompletionRate  float64  `json:"completion_rate"`
+	AveragePriority *float64 `json:"average_priority,omitempty"`
+}
+
+// tagTotals holds the aggregate values used to build TagStats.
+type tagTotals struct {
+	Total           int64
+	Completed       int64
+	AveragePriority *float64
+}
+
+// GetTagStats returns a handler function that reports completion metrics for the
+// unarchived, live tasks carrying a tag, computed with a single join query rather than
+// by loading the tag's tasks.
+func GetTagStats(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tag models.Tag
+		if err := db.First(&tag, "id = ?", c.Param("id")).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+				return
+			}
+			log.Println("Error fetching tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+			return
+		}
+
+		var totals tagTotals
+		if err := db.Table("task_tags").
+			Select(`COUNT(*) AS total,
+				COALESCE(SUM(CASE WHEN tasks.completed = ? THEN 1 ELSE 0 END), 0) AS completed,
+				AVG(tasks.priority) AS average_priority`, true).
+			Joins("JOIN tasks ON tasks.id = task_tags.task_id").
+			Where("task_tags.tag_id = ? AND tasks.deleted = ? AND tasks.archived = ?", tag.ID, false, false).
+			Scan(&totals).Error; err != nil {
+			log.Println("Error counting tag tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+			return
+		}
+
+		stats := TagStats{
+			TagID:           tag.ID,
+			Name:            tag.Name,
+			Total:           totals.Total,
+			Completed:       totals.Completed,
+			Incomplete:      totals.Total - totals.Completed,
+			AveragePriority: totals.AveragePriority,
+		}
+		if totals.Total > 0 {
+			stats.CompletionRate = float64(totals.Completed) / float64(totals.Total)
+		}
+
+		c.JSON(http.StatusOK, stats)
+	}
+}
+
+// WorkloadTag represents the estimated minutes of work attributed to a single tag.
+type WorkloadTag struct {
+	TagID   string `json:"tag_id"`
+	Name    string `json:"name"`
+	Minutes int    `json:"minutes"`
+}
+
+// WorkloadResponse represents the estimated workload for a single day.
+// Tasks with several tags count towards each of their tags in the breakdown.
+type WorkloadResponse struct {
+	Date         string        `json:"date"`
+	TotalMinutes int           `json:"total_minutes"`
+	TaskCount    int           `json:"task_count"`
+	ByTag        []WorkloadTag `json:"by_tag"`
+}
+
+// GetWorkload returns a handler function that sums the estimated minutes of all tasks
+// due on, or recurring on, the requested date in the specified timezone.
+func GetWorkload(db *gorm.DB, location *time.Location, timezone string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		day := time.Now().In(location)
+		if date := c.Query("date"); date != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", date, location)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Date must be in YYYY-MM-DD format"})
+				return
+			}
+			day = parsed
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, location)
+		end := start.AddDate(0, 0, 1)
+
+		var tasks []models.Task
+		if err := db.Preload("Tags").Preload("Frequency").
+			Where("deleted = ? AND archived = ? AND estimated_minutes IS NOT NULL", false, false).
+			Find(&tasks).Error; err != nil {
+			log.Println("Error fetching tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+
+		response := WorkloadResponse{Date: start.Format("2006-01-02"), ByTag: []WorkloadTag{}}
+		byTag := make(map[string]*WorkloadTag)
+
+		for _, task := range tasks {
+			if !scheduledOn(task, start, end, timezone) {
+				continue
+			}
+
+			minutes := *task.EstimatedMinutes
+			response.TotalMinutes += minutes
+			response.TaskCount++
+
+			for _, tag := range task.Tags {
+				entry, ok := byTag[tag.ID]
+				if !ok {
+					entry = &WorkloadTag{TagID: tag.ID, Name: tag.Name}
+					byTag[tag.ID] = entry
+				}
+				entry.Minutes += minutes
+			}
+		}
+
+		for _, entry := range byTag {
+			response.ByTag = append(response.ByTag, *entry)
+		}
+		sort.Slice(response.ByTag, func(i, j int) bool {
+			if response.ByTag[i].Minutes != response.ByTag[j].Minutes {
+				return response.ByTag[i].Minutes > response.ByTag[j].Minutes
+			}
+			return response.ByTag[i].Name < response.ByTag[j].Name
+		})
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// scheduledOn reports whether a task is due, or its frequency fires, between start and end.
+// Paused frequencies never fire.
+func scheduledOn(task models.Task, start, end time.Time, timezone string) bool {
+	if task.DueDate != nil && !task.DueDate.Before(start) && task.DueDate.Before(end) {
+		return true
+	}
+
+	if task.Frequency == nil || !task.Frequency.Enabled {
+		return false
+	}
+
+	schedule, err := task.Frequency.Schedule(timezone)
+	if err != nil {
+		log.Printf("Error parsing schedule for frequency %s: %v", task.Frequency.Name, err)
+		return false
+	}
+
+	next := schedule.Next(start.Add(-time.Second))
+	return !next.IsZero() && next.Before(end)
+}
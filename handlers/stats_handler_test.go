@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+)
+
+func TestGetWorkload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	work := models.Tag{Name: "Work", Color: "#ff0000"}
+	home := models.Tag{Name: "Home", Color: "#00ff00"}
+	db.Create(&work)
+	db.Create(&home)
+
+	daily := models.Frequency{Name: "Daily", Period: "0 9 * * *"}
+	paused := models.Frequency{Name: "Paused", Period: "0 10 * * *"}
+	db.Create(&daily)
+	db.Create(&paused)
+	db.Model(&paused).Update("enabled", false)
+
+	dueMorning := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	dueEvening := time.Date(2025, 3, 14, 18, 30, 0, 0, time.UTC)
+	dueNextDay := time.Date(2025, 3, 15, 9, 0, 0, 0, time.UTC)
+
+	report := models.Task{Name: "Report", DueDate: &dueMorning, EstimatedMinutes: intPtr(45)}
+	groceries := models.Task{Name: "Groceries", DueDate: &dueEvening, EstimatedMinutes: intPtr(30)}
+	standup := models.Task{Name: "Standup", FrequencyID: &daily.ID, EstimatedMinutes: intPtr(15)}
+	tomorrow := models.Task{Name: "Tomorrow", DueDate: &dueNextDay, EstimatedMinutes: intPtr(60)}
+	unestimated := models.Task{Name: "Unestimated", DueDate: &dueMorning}
+	onHold := models.Task{Name: "On hold", FrequencyID: &paused.ID, EstimatedMinutes: intPtr(20)}
+	db.Create(&report)
+	db.Create(&groceries)
+	db.Create(&standup)
+	db.Create(&tomorrow)
+	db.Create(&unestimated)
+	db.Create(&onHold)
+
+	db.Model(&report).Association("Tags").Append(&work)
+	db.Model(&standup).Association("Tags").Append(&work)
+	db.Model(&groceries).Association("Tags").Append(&home)
+
+	r := gin.New()
+	r.GET("/stats/workload", GetWorkload(db, time.UTC, "UTC"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/stats/workload?date=2025-03-14", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response WorkloadResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.TotalMinutes != 90 {
+		t.Errorf("Expected 90 total minutes, got %d", response.TotalMinutes)
+	}
+	if response.TaskCount != 3 {
+		t.Errorf("Expected 3 tasks, got %d", response.TaskCount)
+	}
+	if len(response.ByTag) != 2 {
+		t.Fatalf("Expected 2 tag entries, got %d", len(response.ByTag))
+	}
+	if response.ByTag[0].Name != "Work" || response.ByTag[0].Minutes != 60 {
+		t.Errorf("Expected Work with 60 minutes first, got %s with %d", response.ByTag[0].Name, response.ByTag[0].Minutes)
+	}
+	if response.ByTag[1].Name != "Home" || response.ByTag[1].Minutes != 30 {
+		t.Errorf("Expected Home with 30 minutes second, got %s with %d", response.ByTag[1].Name, response.ByTag[1].Minutes)
+	}
+}
+
+func TestGetWorkloadInvalidDate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.GET("/stats/workload", GetWorkload(db, time.UTC, "UTC"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/stats/workload?date=14/03/2025", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	work := models.Tag{Name: "Work", Color: "#ff0000"}
+	db.Create(&work)
+	daily := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&daily)
+
+	db.Create(&models.Task{Name: "Done", Completed: true, FrequencyID: &daily.ID, Tags: []models.Tag{work}})
+	db.Create(&models.Task{Name: "Open", Tags: []models.Tag{work}})
+	old := models.Task{Name: "Old", Completed: true}
+	db.Create(&old)
+	db.Model(&old).UpdateColumn("updated_at", time.Now().AddDate(0, 0, -10))
+	db.Create(&models.Task{Name: "Gone", Deleted: true})
+
+	r := gin.New()
+	r.GET("/stats", GetStats(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/stats", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var stats TaskStats
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if stats.Total != 3 || stats.Completed != 2 || stats.Incomplete != 1 {
+		t.Errorf("Unexpected totals: %+v", stats)
+	}
+	if stats.CompletionRate7 != 1.0/3 {
+		t.Errorf("Expected 7 day completion rate 1/3, got %f", stats.CompletionRate7)
+	}
+	if stats.CompletionRate30 != 2.0/3 {
+		t.Errorf("Expected 30 day completion rate 2/3, got %f", stats.CompletionRate30)
+	}
+	if len(stats.ByTag) != 1 || stats.ByTag[0].Name != "Work" || stats.ByTag[0].Count != 2 {
+		t.Errorf("Unexpected tag counts: %+v", stats.ByTag)
+	}
+	if len(stats.ByFrequency) != 1 || stats.ByFrequency[0].Name != "Daily" || stats.ByFrequency[0].Count != 1 {
+		t.Errorf("Unexpected frequency counts: %+v", stats.ByFrequency)
+	}
+}
+
+func TestGetStatsIncludeDeleted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	db.Create(&models.Task{Name: "Done", Completed: true})
+	db.Create(&models.Task{Name: "Deleted", Completed: true, Deleted: true})
+
+	r := gin.New()
+	r.GET("/stats", GetStats(db))
+
+	for query, expected := range map[string]int64{"": 1, "?include_deleted=true": 2} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/stats"+query, nil)
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var stats TaskStats
+		json.Unmarshal(w.Body.Bytes(), &stats)
+		if stats.Completed != expected {
+			t.Errorf("Expected %d completed tasks for %q, got %d", expected, query, stats.Completed)
+		}
+	}
+}
+
+func TestGetTagStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	work := models.Tag{Name: "Work", Color: "#ff0000"}
+	db.Create(&work)
+	empty := models.Tag{Name: "Empty", Color: "#00ff00"}
+	db.Create(&empty)
+
+	db.Create(&models.Task{Name: "Done", Completed: true, Priority: intPtr(1), Tags: []models.Tag{work}})
+	db.Create(&models.Task{Name: "Open", Priority: intPtr(4), Tags: []models.Tag{work}})
+	db.Create(&models.Task{Name: "Unprioritized", Tags: []models.Tag{work}})
+	db.Create(&models.Task{Name: "Archived", Completed: true, Archived: true, Tags: []models.Tag{work}})
+	db.Create(&models.Task{Name: "Gone", Completed: true, Deleted: true, Tags: []models.Tag{work}})
+	db.Create(&models.Task{Name: "Untagged", Completed: true, Priority: intPtr(5)})
+
+	r := gin.New()
+	r.GET("/tags/:id/stats", GetTagStats(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tags/"+work.ID+"/stats", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var stats TagStats
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if stats.TagID != work.ID || stats.Name != "Work" {
+		t.Errorf("Unexpected tag: %+v", stats)
+	}
+	if stats.Total != 3 || stats.Completed != 1 || stats.Incomplete != 2 {
+		t.Errorf("Unexpected totals: %+v", stats)
+	}
+	if stats.CompletionRate != 1.0/3 {
+		t.Errorf("Expected completion rate 1/3, got %f", stats.CompletionRate)
+	}
+	if stats.AveragePriority == nil || *stats.AveragePriority != 2.5 {
+		t.Errorf("Expected average priority 2.5, got %v", stats.AveragePriority)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tags/"+empty.ID+"/stats", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "average_priority") {
+		t.Errorf("Expected no average priority for a tag without tasks, got %s", w.Body.String())
+	}
+	stats = TagStats{}
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if stats.Total != 0 || stats.CompletionRate != 0 {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tags/missing/stats", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -4,37 +4,150 @@ import (
 	"crypto/rand"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/metrics"
 	"github.com/jhoffmann/dailies/models"
 	"gorm.io/gorm"
 )
 
-// generateRandomColor generates a random hex color code.
+// Saturation and lightness bands of colors generated by the readable tag color strategy.
+const (
+	readableMinSaturation = 0.45
+	readableMaxSaturation = 0.80
+	readableMinLightness  = 0.35
+	readableMaxLightness  = 0.65
+)
+
+// generateRandomColor generates a random hex color code, drawing from the
+// configured tag palette when one is selected and otherwise following the
+// configured tag color strategy.
 func generateRandomColor() string {
-	bytes := make([]byte, 3)
+	bytes := make([]byte, 4)
 	rand.Read(bytes)
+
+	if palette := models.TagColors(); len(palette) > 0 {
+		return palette[int(bytes[0])%len(palette)]
+	}
+	if models.TagColorStrategy() == models.TagColorStrategyReadable {
+		hue := float64(int(bytes[0])<<8|int(bytes[1])) / 65536 * 360
+		saturation := readableMinSaturation + float64(bytes[2])/255*(readableMaxSaturation-readableMinSaturation)
+		lightness := readableMinLightness + float64(bytes[3])/255*(readableMaxLightness-readableMinLightness)
+		return hslToHex(hue, saturation, lightness)
+	}
 	return fmt.Sprintf("#%02x%02x%02x", bytes[0], bytes[1], bytes[2])
 }
 
+// hslToHex converts a hue in degrees and a saturation and lightness between 0 and 1
+// into a hex color code.
+func hslToHex(hue, saturation, lightness float64) string {
+	chroma := (1 - math.Abs(2*lightness-1)) * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := lightness - chroma/2
+
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+	case hue < 120:
+		r, g, b = x, chroma, 0
+	case hue < 180:
+		r, g, b = 0, chroma, x
+	case hue < 240:
+		r, g, b = 0, x, chroma
+	case hue < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	channel := func(v float64) int {
+		return int(math.Round((v + m) * 255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", channel(r), channel(g), channel(b))
+}
+
 // validateHexColor validates that a string is a valid hex color.
 func validateHexColor(color string) bool {
 	hexPattern := regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
 	return hexPattern.MatchString(color)
 }
 
+// findTagNameConflict returns the tag, including tags in the trash, whose name matches
+// name case-insensitively, other than the tag with excludeID. It returns nil when the
+// name is free.
+func findTagNameConflict(db *gorm.DB, name, excludeID string) (*models.Tag, error) {
+	var tag models.Tag
+	err := db.Unscoped().Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeID).First(&tag).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// respondTagNameConflict responds 409 for a tag name held by existing. When that tag is
+// in the trash, the response names it so the caller can restore it instead.
+func respondTagNameConflict(c *gin.Context, existing *models.Tag) {
+	if existing.DeletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  fmt.Sprintf("Tag '%s' is in the trash, restore it with POST /api/tags/%s/restore", existing.Name, existing.ID),
+			"tag_id": existing.ID,
+		})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Tag '%s' already exists", existing.Name)})
+}
+
+// findOrCreateTagsByName resolves tag names case-insensitively, restoring tags that are
+// in the trash and creating tags that do not exist with a generated color.
+func findOrCreateTagsByName(db *gorm.DB, names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		var tag models.Tag
+		err := db.Unscoped().Where("LOWER(name) = LOWER(?)", name).First(&tag).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			tag = models.Tag{Name: name, Color: generateRandomColor(), NotificationsEnabled: true}
+			if err := db.Create(&tag).Error; err != nil {
+				return nil, err
+			}
+		case err != nil:
+			return nil, err
+		case tag.DeletedAt.Valid:
+			if err := db.Unscoped().Model(&tag).Update("deleted_at", nil).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
 // GetTags returns a handler function for retrieving all tags with optional filtering.
 func GetTags(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var tags []models.Tag
+		tags := []models.Tag{}
 		query := db.Model(&models.Tag{})
 
 		// Filter by name (partial matching)
 		if name := c.Query("name"); name != "" {
-			query = query.Where("name LIKE ?", "%"+name+"%")
+			query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+name+"%")
 		}
 
 		// Default sorting by name
@@ -46,10 +159,53 @@ func GetTags(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		counts, err := tagTaskCounts(db)
+		if err != nil {
+			log.Println("Error counting tag tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+			return
+		}
+		for i := range tags {
+			tags[i].TaskCount = counts[tags[i].ID]
+		}
+
+		// Optionally order by popularity, keeping name order among equally used tags
+		if c.Query("sort") == "usage" {
+			sort.SliceStable(tags, func(i, j int) bool {
+				return tags[i].TaskCount > tags[j].TaskCount
+			})
+		}
+
 		c.JSON(http.StatusOK, tags)
 	}
 }
 
+// tagTaskCount holds the number of live tasks carrying a tag.
+type tagTaskCount struct {
+	TagID string
+	Count int
+}
+
+// tagTaskCounts returns the number of live tasks carrying each tag, keyed by tag ID,
+// using a single grouped query over the task_tags join table.
+func tagTaskCounts(db *gorm.DB) (map[string]int, error) {
+	var rows []tagTaskCount
+	if err := db.Table("task_tags").
+		Select("task_tags.tag_id, COUNT(*) AS count").
+		Joins("JOIN tasks ON tasks.id = task_tags.task_id").
+		Where("tasks.deleted = ?", false).
+		Group("task_tags.tag_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.TagID] = row.Count
+	}
+	return counts, nil
+}
+
 // GetTag returns a handler function for retrieving a specific tag by ID.
 func GetTag(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -70,10 +226,204 @@ func GetTag(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// GetTagByName returns a handler function for retrieving the tag whose name matches
+// exactly, ignoring case.
+func GetTagByName(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.TrimSpace(c.Param("name"))
+		var tag models.Tag
+
+		if err := db.Preload("Tasks").Where("LOWER(name) = LOWER(?)", name).First(&tag).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+				return
+			}
+			log.Println("Error fetching tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+			return
+		}
+
+		c.JSON(http.StatusOK, tag)
+	}
+}
+
+// maxTagSuggestions is the number of tags returned by SuggestTags.
+const maxTagSuggestions = 5
+
+// TagSuggestion represents a tag suggested for a task name, with the number of
+// similarly named tasks it is applied to.
+type TagSuggestion struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Count int    `json:"count"`
+}
+
+// nameTokens splits a task name into lowercase words for similarity matching.
+func nameTokens(name string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		tokens[word] = true
+	}
+	return tokens
+}
+
+// SuggestTags returns a handler function that suggests tags for ?name= based on the tags
+// of existing tasks sharing at least one word with it, ranked by how often they are applied.
+func SuggestTags(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := nameTokens(c.Query("name"))
+		if len(query) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+			return
+		}
+
+		var tasks []models.Task
+		if err := db.Preload("Tags").Where("deleted = ?", false).Find(&tasks).Error; err != nil {
+			log.Println("Error fetching tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+
+		counts := make(map[string]*TagSuggestion)
+		for _, task := range tasks {
+			overlaps := false
+			for token := range nameTokens(task.Name) {
+				if query[token] {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				continue
+			}
+
+			for _, tag := range task.Tags {
+				suggestion, ok := counts[tag.ID]
+				if !ok {
+					suggestion = &TagSuggestion{ID: tag.ID, Name: tag.Name, Color: tag.Color}
+					counts[tag.ID] = suggestion
+				}
+				suggestion.Count++
+			}
+		}
+
+		suggestions := []TagSuggestion{}
+		for _, suggestion := range counts {
+			suggestions = append(suggestions, *suggestion)
+		}
+		sort.Slice(suggestions, func(i, j int) bool {
+			if suggestions[i].Count != suggestions[j].Count {
+				return suggestions[i].Count > suggestions[j].Count
+			}
+			return suggestions[i].Name < suggestions[j].Name
+		})
+		if len(suggestions) > maxTagSuggestions {
+			suggestions = suggestions[:maxTagSuggestions]
+		}
+
+		c.JSON(http.StatusOK, suggestions)
+	}
+}
+
+// TagIntersectionEntry represents how many tasks would remain when adding a tag to a selection.
+type TagIntersectionEntry struct {
+	TagID string `json:"tag_id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// TagIntersectionResponse represents the number of tasks carrying all selected tags.
+type TagIntersectionResponse struct {
+	TagIDs    []string               `json:"tag_ids"`
+	Count     int64                  `json:"count"`
+	Breakdown []TagIntersectionEntry `json:"breakdown,omitempty"`
+}
+
+// GetTagIntersection returns a handler function that counts the active tasks carrying
+// every tag in ?ids=. With ?breakdown=true it also reports, for each other tag, how many
+// of those tasks would remain if that tag were added to the selection.
+func GetTagIntersection(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ids []string
+		seen := make(map[string]bool)
+		for _, id := range strings.Split(c.Query("ids"), ",") {
+			if id = strings.TrimSpace(id); id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "At least one tag ID is required"})
+			return
+		}
+
+		// Tasks whose tags include every selected tag
+		matching := db.Table("task_tags").
+			Select("task_tags.task_id").
+			Joins("JOIN tasks ON tasks.id = task_tags.task_id").
+			Where("tasks.deleted = ? AND tasks.archived = ? AND task_tags.tag_id IN ?", false, false, ids).
+			Group("task_tags.task_id").
+			Having("COUNT(DISTINCT task_tags.tag_id) = ?", len(ids))
+
+		response := TagIntersectionResponse{TagIDs: ids}
+		if err := db.Table("(?) AS matching", matching).Count(&response.Count).Error; err != nil {
+			log.Println("Error counting tag intersection:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+			return
+		}
+
+		if breakdown, _ := strconv.ParseBool(c.Query("breakdown")); breakdown {
+			response.Breakdown = []TagIntersectionEntry{}
+			if err := db.Table("task_tags").
+				Select("tags.id AS tag_id, tags.name AS name, COUNT(*) AS count").
+				Joins("JOIN tags ON tags.id = task_tags.tag_id AND tags.deleted_at IS NULL").
+				Where("task_tags.task_id IN (?) AND task_tags.tag_id NOT IN ?", matching, ids).
+				Group("tags.id, tags.name").
+				Order("count DESC, tags.name").
+				Scan(&response.Breakdown).Error; err != nil {
+				log.Println("Error computing tag intersection breakdown:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
 // CreateTagRequest represents the request payload for creating a tag.
 type CreateTagRequest struct {
-	Name  string  `json:"name" binding:"required"`
-	Color *string `json:"color,omitempty"`
+	Name                 string  `json:"name" binding:"required"`
+	Color                *string `json:"color,omitempty"`
+	NotificationsEnabled *bool   `json:"notifications_enabled,omitempty"`
+	AutoEscalate         *bool   `json:"auto_escalate,omitempty"`
+}
+
+// TagPaletteResponse represents the active tag palette. Colors is empty when tag colors
+// are generated randomly.
+type TagPaletteResponse struct {
+	Name       string   `json:"name"`
+	Colors     []string `json:"colors"`
+	Restricted bool     `json:"restricted"`
+}
+
+// GetTagPalette returns a handler function for retrieving the active tag palette and
+// whether tags are restricted to its colors.
+func GetTagPalette() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		colors := models.TagColors()
+		if colors == nil {
+			colors = []string{}
+		}
+		c.JSON(http.StatusOK, TagPaletteResponse{
+			Name:       models.TagPaletteName(),
+			Colors:     colors,
+			Restricted: models.TagColorsRestricted(),
+		})
+	}
 }
 
 // CreateTag returns a handler function for creating a new tag.
@@ -93,17 +443,35 @@ func CreateTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Color must be a valid hex color (e.g., #ff0000)"})
 				return
 			}
+			if !models.ValidTagColor(color) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Color must be one of the tag palette colors"})
+				return
+			}
 		} else {
 			color = generateRandomColor()
 		}
 
 		tag := models.Tag{
-			Name:  strings.TrimSpace(req.Name),
-			Color: color,
+			Name:                 strings.TrimSpace(req.Name),
+			Color:                color,
+			NotificationsEnabled: req.NotificationsEnabled == nil || *req.NotificationsEnabled,
+			AutoEscalate:         req.AutoEscalate != nil && *req.AutoEscalate,
+		}
+
+		// Tag names are unique regardless of case
+		existing, err := findTagNameConflict(db, tag.Name, "")
+		if err != nil {
+			log.Println("Error checking tag name:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
+			return
+		}
+		if existing != nil {
+			respondTagNameConflict(c, existing)
+			return
 		}
 
 		if err := db.Create(&tag).Error; err != nil {
-			if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "duplicate key") {
+			if isDuplicateKeyError(err) {
 				c.JSON(http.StatusConflict, gin.H{"error": "Tag with this name already exists"})
 				return
 			}
@@ -112,6 +480,15 @@ func CreateTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 			return
 		}
 
+		// GORM replaces a false value with the column default on insert, so mute separately
+		if req.NotificationsEnabled != nil && !*req.NotificationsEnabled {
+			if err := db.Model(&tag).Update("notifications_enabled", false).Error; err != nil {
+				log.Println("Error muting tag:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
+				return
+			}
+		}
+
 		// Broadcast WebSocket event
 		if len(wsManager) > 0 && wsManager[0] != nil {
 			if ws, ok := wsManager[0].(interface {
@@ -127,8 +504,10 @@ func CreateTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 
 // UpdateTagRequest represents the request payload for updating a tag.
 type UpdateTagRequest struct {
-	Name  *string `json:"name,omitempty"`
-	Color *string `json:"color,omitempty"`
+	Name                 *string `json:"name,omitempty"`
+	Color                *string `json:"color,omitempty"`
+	NotificationsEnabled *bool   `json:"notifications_enabled,omitempty"`
+	AutoEscalate         *bool   `json:"auto_escalate,omitempty"`
 }
 
 // UpdateTag returns a handler function for updating an existing tag.
@@ -159,6 +538,24 @@ func UpdateTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Color must be a valid hex color (e.g., #ff0000)"})
 				return
 			}
+			if !models.ValidTagColor(color) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Color must be one of the tag palette colors"})
+				return
+			}
+		}
+
+		// Tag names are unique regardless of case
+		if req.Name != nil {
+			existing, err := findTagNameConflict(db, strings.TrimSpace(*req.Name), tag.ID)
+			if err != nil {
+				log.Println("Error checking tag name:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag"})
+				return
+			}
+			if existing != nil {
+				respondTagNameConflict(c, existing)
+				return
+			}
 		}
 
 		// Update fields
@@ -169,10 +566,16 @@ func UpdateTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 		if req.Color != nil {
 			updates["color"] = strings.TrimSpace(*req.Color)
 		}
+		if req.NotificationsEnabled != nil {
+			updates["notifications_enabled"] = *req.NotificationsEnabled
+		}
+		if req.AutoEscalate != nil {
+			updates["auto_escalate"] = *req.AutoEscalate
+		}
 
 		if len(updates) > 0 {
 			if err := db.Model(&tag).Updates(updates).Error; err != nil {
-				if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "duplicate key") {
+				if isDuplicateKeyError(err) {
 					c.JSON(http.StatusConflict, gin.H{"error": "Tag with this name already exists"})
 					return
 				}
@@ -202,7 +605,8 @@ func UpdateTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 	}
 }
 
-// DeleteTag returns a handler function for deleting a tag.
+// DeleteTag returns a handler function for soft deleting a tag. Task associations are
+// kept so that restoring the tag brings them back.
 func DeleteTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
@@ -221,13 +625,6 @@ func DeleteTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 		// Store tag data for WebSocket event before deletion
 		tagForEvent := tag
 
-		// Clear tag associations from tasks (this will be handled by CASCADE, but being explicit)
-		if err := db.Model(&tag).Association("Tasks").Clear(); err != nil {
-			log.Println("Error clearing tag associations:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear tag associations"})
-			return
-		}
-
 		if err := db.Delete(&tag).Error; err != nil {
 			log.Println("Error deleting tag:", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag"})
@@ -246,3 +643,298 @@ func DeleteTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 		c.JSON(http.StatusNoContent, nil)
 	}
 }
+
+// MergeTagRequest represents the request payload for merging a tag into another.
+type MergeTagRequest struct {
+	Into string `json:"into" binding:"required"`
+}
+
+// MergeTag returns a handler function that merges a tag into another. Tasks carrying the
+// source tag are given the target tag instead, without duplicating existing associations,
+// and the source tag is deleted. The surviving tag is returned.
+func MergeTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var req MergeTagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Into == id {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot merge a tag into itself"})
+			return
+		}
+
+		var source models.Tag
+		if err := db.First(&source, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+				return
+			}
+			log.Println("Error fetching tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+			return
+		}
+
+		var target models.Tag
+		if err := db.First(&target, "id = ?", req.Into).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Target tag not found"})
+				return
+			}
+			log.Println("Error fetching tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Move associations to the target, skipping tasks that already carry it
+			if err := tx.Exec(`INSERT INTO task_tags (task_id, tag_id)
+				SELECT task_id, ? FROM task_tags
+				WHERE tag_id = ? AND task_id NOT IN (SELECT task_id FROM task_tags WHERE tag_id = ?)`,
+				target.ID, source.ID, target.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&source).Error
+		})
+		if err != nil {
+			log.Println("Error merging tags:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge tags"})
+			return
+		}
+
+		// Reload with associations
+		if err := db.Preload("Tasks").First(&target, "id = ?", target.ID).Error; err != nil {
+			log.Println("Error reloading tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload tag"})
+			return
+		}
+
+		// Broadcast WebSocket events
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("tag_delete", source)
+				ws.Broadcast("task_list_refresh", target)
+			}
+		}
+
+		c.JSON(http.StatusOK, target)
+	}
+}
+
+// TagTasksRequest represents the request payload for assigning a tag to, or removing it
+// from, several tasks.
+type TagTasksRequest struct {
+	TaskIDs []string `json:"task_ids" binding:"required,min=1"`
+}
+
+// TagAssignmentResult represents the outcome of assigning or unassigning a tag for a single task.
+type TagAssignmentResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AssignTag returns a handler function that adds a tag to several tasks in a single
+// transaction, reporting success or failure for each ID. Tasks already carrying the tag
+// are left as they are.
+func AssignTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return setTagAssignment(db, true, wsManager...)
+}
+
+// UnassignTag returns a handler function that removes a tag from several tasks in a
+// single transaction, reporting success or failure for each ID.
+func UnassignTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return setTagAssignment(db, false, wsManager...)
+}
+
+// setTagAssignment returns a handler function that adds the tag to, or removes it from,
+// each live task in the request.
+func setTagAssignment(db *gorm.DB, assign bool, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var req TagTasksRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var tag models.Tag
+		if err := db.First(&tag, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+				return
+			}
+			log.Println("Error fetching tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+			return
+		}
+
+		results := make([]TagAssignmentResult, 0, len(req.TaskIDs))
+		updated := 0
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, taskID := range req.TaskIDs {
+				var count int64
+				if err := tx.Model(&models.Task{}).Where("id = ? AND deleted = ?", taskID, false).Count(&count).Error; err != nil {
+					return err
+				}
+				if count == 0 {
+					results = append(results, TagAssignmentResult{ID: taskID, Error: "Task not found"})
+					continue
+				}
+
+				var err error
+				if assign {
+					err = tx.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", taskID, tag.ID).Error
+				} else {
+					err = tx.Exec("DELETE FROM task_tags WHERE task_id = ? AND tag_id = ?", taskID, tag.ID).Error
+				}
+				if err != nil {
+					return err
+				}
+				results = append(results, TagAssignmentResult{ID: taskID, Success: true})
+				updated++
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("Error updating tag assignments:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag assignments"})
+			return
+		}
+
+		// Broadcast a single WebSocket event for the whole batch
+		if updated > 0 && len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_list_refresh", results)
+			}
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// RestoreTag returns a handler function for restoring a soft deleted tag from the trash.
+func RestoreTag(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		var tag models.Tag
+		if err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&tag, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Deleted tag not found"})
+				return
+			}
+			log.Println("Error fetching tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+			return
+		}
+
+		if err := db.Unscoped().Model(&tag).Update("deleted_at", nil).Error; err != nil {
+			log.Println("Error restoring tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore tag"})
+			return
+		}
+
+		// Reload the tag
+		if err := db.First(&tag, "id = ?", id).Error; err != nil {
+			log.Println("Error reloading tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload tag"})
+			return
+		}
+
+		// Broadcast WebSocket event
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("tag_create", tag)
+			}
+		}
+
+		c.JSON(http.StatusOK, tag)
+	}
+}
+
+// TagCompletionResult represents the response payload for bulk completion changes
+// applied to all tasks carrying a tag.
+type TagCompletionResult struct {
+	TagID   string `json:"tag_id"`
+	Total   int64  `json:"total"`
+	Updated int64  `json:"updated"`
+}
+
+// CompleteAllTagTasks returns a handler function for marking every task carrying a tag as completed.
+func CompleteAllTagTasks(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return setTagTasksCompleted(db, true, wsManager...)
+}
+
+// UncompleteAllTagTasks returns a handler function for marking every task carrying a tag as incomplete.
+func UncompleteAllTagTasks(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return setTagTasksCompleted(db, false, wsManager...)
+}
+
+// setTagTasksCompleted returns a handler function that sets the completion state of all
+// non-deleted tasks carrying the tag in a single transaction.
+func setTagTasksCompleted(db *gorm.DB, completed bool, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		var tag models.Tag
+		if err := db.First(&tag, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+				return
+			}
+			log.Println("Error fetching tag:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+			return
+		}
+
+		result := TagCompletionResult{TagID: tag.ID}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			taggedTasks := tx.Table("task_tags").Select("task_id").Where("tag_id = ?", tag.ID)
+
+			if err := tx.Model(&models.Task{}).
+				Where("deleted = ? AND id IN (?)", false, taggedTasks).
+				Count(&result.Total).Error; err != nil {
+				return err
+			}
+
+			update := tx.Model(&models.Task{}).
+				Where("deleted = ? AND completed = ? AND id IN (?)", false, !completed, taggedTasks).
+				Update("completed", completed)
+			if update.Error != nil {
+				return update.Error
+			}
+			result.Updated = update.RowsAffected
+			return nil
+		})
+		if err != nil {
+			log.Println("Error updating tag tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tasks"})
+			return
+		}
+		if completed {
+			metrics.TasksCompleted.Add(float64(result.Updated))
+		}
+
+		// Broadcast a single WebSocket event for the whole batch
+		if result.Updated > 0 && len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_list_refresh", result)
+			}
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
 )
 
 func TestGetTags(t *testing.T) {
@@ -34,6 +37,51 @@ func TestGetTags(t *testing.T) {
 	}
 }
 
+func TestGetTagsTaskCount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	rare := models.Tag{Name: "alpha", Color: "#111111"}
+	popular := models.Tag{Name: "beta", Color: "#222222"}
+	unused := models.Tag{Name: "gamma", Color: "#333333"}
+	db.Create(&rare)
+	db.Create(&popular)
+	db.Create(&unused)
+
+	db.Create(&models.Task{Name: "One", Tags: []models.Tag{rare, popular}})
+	db.Create(&models.Task{Name: "Two", Tags: []models.Tag{popular}})
+	db.Create(&models.Task{Name: "Deleted", Deleted: true, Tags: []models.Tag{rare}})
+
+	r := gin.New()
+	r.GET("/tags", GetTags(db))
+
+	tests := []struct {
+		query    string
+		expected []string
+		counts   []int
+	}{
+		{"", []string{"alpha", "beta", "gamma"}, []int{1, 2, 0}},
+		{"?sort=usage", []string{"beta", "alpha", "gamma"}, []int{2, 1, 0}},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/tags"+tt.query, nil)
+		r.ServeHTTP(w, req)
+
+		var tags []models.Tag
+		json.Unmarshal(w.Body.Bytes(), &tags)
+		if len(tags) != len(tt.expected) {
+			t.Fatalf("Expected %d tags for %q, got %d", len(tt.expected), tt.query, len(tags))
+		}
+		for i, tag := range tags {
+			if tag.Name != tt.expected[i] || tag.TaskCount != tt.counts[i] {
+				t.Errorf("Expected %s with %d tasks at %d for %q, got %s with %d", tt.expected[i], tt.counts[i], i, tt.query, tag.Name, tag.TaskCount)
+			}
+		}
+	}
+}
+
 func TestGetTagNotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupTestHandlerDB(t)
@@ -146,6 +194,67 @@ func TestGenerateRandomColor(t *testing.T) {
 	}
 }
 
+func TestGenerateReadableColor(t *testing.T) {
+	if err := models.SetTagColorStrategy(models.TagColorStrategyReadable); err != nil {
+		t.Fatalf("Failed to select strategy: %v", err)
+	}
+	t.Cleanup(func() { models.SetTagColorStrategy(models.TagColorStrategyRandom) })
+
+	// Rounding to 8-bit channels moves colors slightly off the exact band
+	const tolerance = 0.02
+	for i := 0; i < 500; i++ {
+		color := generateRandomColor()
+		if !validateHexColor(color) {
+			t.Fatalf("Generated color failed validation: %s", color)
+		}
+
+		saturation, lightness := hexSaturationLightness(t, color)
+		if lightness < readableMinLightness-tolerance || lightness > readableMaxLightness+tolerance {
+			t.Errorf("Expected lightness of %s within the readable band, got %.3f", color, lightness)
+		}
+		if saturation < readableMinSaturation-tolerance || saturation > readableMaxSaturation+tolerance {
+			t.Errorf("Expected saturation of %s within the readable band, got %.3f", color, saturation)
+		}
+	}
+}
+
+func TestHSLToHex(t *testing.T) {
+	tests := []struct {
+		hue, saturation, lightness float64
+		expected                   string
+	}{
+		{0, 1, 0.5, "#ff0000"},
+		{120, 1, 0.5, "#00ff00"},
+		{240, 1, 0.5, "#0000ff"},
+		{0, 0, 1, "#ffffff"},
+		{0, 0, 0, "#000000"},
+		{210, 0.5, 0.4, "#336699"},
+	}
+
+	for _, tt := range tests {
+		if got := hslToHex(tt.hue, tt.saturation, tt.lightness); got != tt.expected {
+			t.Errorf("hslToHex(%v, %v, %v) = %s, want %s", tt.hue, tt.saturation, tt.lightness, got, tt.expected)
+		}
+	}
+}
+
+// hexSaturationLightness returns the HSL saturation and lightness of a hex color.
+func hexSaturationLightness(t *testing.T, color string) (float64, float64) {
+	t.Helper()
+	var r, g, b int
+	if _, err := fmt.Sscanf(color, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		t.Fatalf("Failed to parse color %s: %v", color, err)
+	}
+
+	high := float64(max(r, g, b)) / 255
+	low := float64(min(r, g, b)) / 255
+	lightness := (high + low) / 2
+	if high == low {
+		return 0, lightness
+	}
+	return (high - low) / (1 - math.Abs(2*lightness-1)), lightness
+}
+
 func TestValidateHexColor(t *testing.T) {
 	// Test valid hex colors
 	validColors := []string{
@@ -318,6 +427,55 @@ func TestUpdateTagDuplicateName(t *testing.T) {
 	}
 }
 
+func TestTagNamesCaseInsensitive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	work := models.Tag{Name: "Work", Color: "#ff0000"}
+	home := models.Tag{Name: "Home", Color: "#00ff00"}
+	db.Create(&work)
+	db.Create(&home)
+
+	r := gin.New()
+	r.GET("/tags", GetTags(db))
+	r.POST("/tags", CreateTag(db))
+	r.PUT("/tags/:id", UpdateTag(db))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{"Create differing by case", "POST", "/tags", `{"name": "work"}`, http.StatusConflict},
+		{"Rename differing by case", "PUT", "/tags/" + home.ID, `{"name": "WORK"}`, http.StatusConflict},
+		{"Change own name's case", "PUT", "/tags/" + work.ID, `{"name": "work"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tags?name=WOR", nil)
+	r.ServeHTTP(w, req)
+
+	var tags []models.Tag
+	json.Unmarshal(w.Body.Bytes(), &tags)
+	if len(tags) != 1 || tags[0].ID != work.ID {
+		t.Errorf("Expected name filter to match 'work' case-insensitively, got %d tags", len(tags))
+	}
+}
+
 func TestDeleteTag(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupTestHandlerDB(t)
@@ -344,3 +502,558 @@ func TestDeleteTag(t *testing.T) {
 		t.Error("Expected tag to be deleted, but it still exists")
 	}
 }
+
+func TestTagNameHeldByTrashedTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	work := models.Tag{Name: "Work", Color: "#ff0000"}
+	home := models.Tag{Name: "Home", Color: "#00ff00"}
+	db.Create(&work)
+	db.Create(&home)
+	task := models.Task{Name: "Task", Tags: []models.Tag{work}}
+	db.Create(&task)
+	db.Delete(&work)
+
+	r := gin.New()
+	r.POST("/tags", CreateTag(db))
+	r.PUT("/tags/:id", UpdateTag(db))
+	r.POST("/tags/:id/restore", RestoreTag(db))
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{"POST", "/tags"},
+		{"PUT", "/tags/" + home.ID},
+	}
+	for _, request := range requests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(request.method, request.path, bytes.NewBufferString(`{"name": "work"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("%s %s: expected status %d, got %d", request.method, request.path, http.StatusConflict, w.Code)
+		}
+		var response map[string]string
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if response["tag_id"] != work.ID || !strings.Contains(response["error"], "/restore") {
+			t.Errorf("%s %s: expected the trashed tag to be pointed at, got %v", request.method, request.path, response)
+		}
+	}
+
+	// The trashed tag and its associations are kept and can be restored
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tags/"+work.ID+"/restore", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d restoring the tag, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var count int64
+	db.Table("task_tags").Where("tag_id = ? AND task_id = ?", work.ID, task.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected the restored tag to keep its task, got %d associations", count)
+	}
+}
+
+// recordingBroadcaster captures WebSocket events broadcast by handlers.
+type recordingBroadcaster struct {
+	events   []any
+	payloads []any
+}
+
+func (b *recordingBroadcaster) Broadcast(eventType any, data any) {
+	b.events = append(b.events, eventType)
+	b.payloads = append(b.payloads, data)
+}
+
+func TestAssignAndUnassignTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	tag := models.Tag{Name: "Work", Color: "#ff0000"}
+	db.Create(&tag)
+	tagged := models.Task{Name: "Tagged", Tags: []models.Tag{tag}}
+	untagged := models.Task{Name: "Untagged"}
+	deleted := models.Task{Name: "Deleted", Deleted: true}
+	db.Create(&tagged)
+	db.Create(&untagged)
+	db.Create(&deleted)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/tags/:id/assign", AssignTag(db, broadcaster))
+	r.POST("/tags/:id/unassign", UnassignTag(db, broadcaster))
+
+	post := func(path string, taskIDs ...string) []TagAssignmentResult {
+		body, _ := json.Marshal(TagTasksRequest{TaskIDs: taskIDs})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var results []TagAssignmentResult
+		json.Unmarshal(w.Body.Bytes(), &results)
+		return results
+	}
+	taggedTasks := func() int64 {
+		var count int64
+		db.Table("task_tags").Where("tag_id = ?", tag.ID).Count(&count)
+		return count
+	}
+
+	results := post("/tags/"+tag.ID+"/assign", tagged.ID, untagged.ID, deleted.ID)
+	if len(results) != 3 || !results[0].Success || !results[1].Success || results[2].Success {
+		t.Errorf("Unexpected assign results: %+v", results)
+	}
+	if count := taggedTasks(); count != 2 {
+		t.Errorf("Expected 2 tagged tasks, got %d", count)
+	}
+
+	post("/tags/"+tag.ID+"/unassign", tagged.ID, untagged.ID)
+	if count := taggedTasks(); count != 0 {
+		t.Errorf("Expected no tagged tasks, got %d", count)
+	}
+
+	if len(broadcaster.events) != 2 || broadcaster.events[0] != "task_list_refresh" {
+		t.Errorf("Expected a list refresh per request, got %v", broadcaster.events)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tags/missing/assign", bytes.NewBufferString(`{"task_ids": ["`+tagged.ID+`"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown tag, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestCompleteAllTagTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	home := models.Tag{Name: "Home", Color: "#ff0000"}
+	work := models.Tag{Name: "Work", Color: "#00ff00"}
+	db.Create(&home)
+	db.Create(&work)
+
+	task1 := models.Task{Name: "Dishes"}
+	task2 := models.Task{Name: "Laundry"}
+	task3 := models.Task{Name: "Report"}
+	task4 := models.Task{Name: "Untagged"}
+	task5 := models.Task{Name: "Deleted", Deleted: true}
+	db.Create(&task1)
+	db.Create(&task2)
+	db.Create(&task3)
+	db.Create(&task4)
+	db.Create(&task5)
+
+	db.Model(&task1).Association("Tags").Append(&home)
+	db.Model(&task2).Association("Tags").Append([]models.Tag{home, work})
+	db.Model(&task3).Association("Tags").Append(&work)
+	db.Model(&task5).Association("Tags").Append(&home)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/tags/:id/complete-all", CompleteAllTagTasks(db, broadcaster))
+	r.POST("/tags/:id/uncomplete-all", UncompleteAllTagTasks(db, broadcaster))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tags/"+home.ID+"/complete-all", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result TagCompletionResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Total != 2 || result.Updated != 2 {
+		t.Errorf("Expected total 2 and updated 2, got total %d and updated %d", result.Total, result.Updated)
+	}
+
+	expected := map[string]bool{task1.ID: true, task2.ID: true, task3.ID: false, task4.ID: false, task5.ID: false}
+	for id, completed := range expected {
+		var task models.Task
+		db.First(&task, "id = ?", id)
+		if task.Completed != completed {
+			t.Errorf("Expected task '%s' completed=%v, got %v", task.Name, completed, task.Completed)
+		}
+	}
+
+	if len(broadcaster.events) != 1 {
+		t.Errorf("Expected 1 broadcast, got %d", len(broadcaster.events))
+	}
+
+	// Completing again should not touch any rows
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/tags/"+home.ID+"/complete-all", nil)
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Total != 2 || result.Updated != 0 {
+		t.Errorf("Expected total 2 and updated 0, got total %d and updated %d", result.Total, result.Updated)
+	}
+
+	// Uncomplete the work tag, which only affects one of the completed tasks
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/tags/"+work.ID+"/uncomplete-all", nil)
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Total != 2 || result.Updated != 1 {
+		t.Errorf("Expected total 2 and updated 1, got total %d and updated %d", result.Total, result.Updated)
+	}
+
+	var laundry models.Task
+	db.First(&laundry, "id = ?", task2.ID)
+	if laundry.Completed {
+		t.Error("Expected 'Laundry' to be incomplete after uncompleting the work tag")
+	}
+}
+
+func TestCompleteAllTagTasksNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/tags/:id/complete-all", CompleteAllTagTasks(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tags/non-existent/complete-all", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestCreateTagWithColorblindPalette(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	if err := models.SetTagPalette(models.TagPaletteColorblind); err != nil {
+		t.Fatalf("Failed to select palette: %v", err)
+	}
+	t.Cleanup(func() { models.SetTagPalette(models.TagPaletteDefault) })
+
+	palette := make(map[string]bool)
+	for _, color := range models.TagColors() {
+		palette[color] = true
+	}
+
+	r := gin.New()
+	r.POST("/tags", CreateTag(db))
+
+	for _, name := range []string{"One", "Two", "Three", "Four", "Five"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tags", bytes.NewBufferString(`{"name": "`+name+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+
+		var tag models.Tag
+		json.Unmarshal(w.Body.Bytes(), &tag)
+		if !palette[tag.Color] {
+			t.Errorf("Expected color from colorblind palette, got %s", tag.Color)
+		}
+	}
+}
+
+func TestTagPaletteRestriction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	models.SetTagPalette(models.TagPaletteColorblind)
+	if err := models.SetTagColorsRestricted(true); err != nil {
+		t.Fatalf("Failed to restrict tag colors: %v", err)
+	}
+	t.Cleanup(func() {
+		models.SetTagColorsRestricted(false)
+		models.SetTagPalette(models.TagPaletteDefault)
+	})
+
+	tag := models.Tag{Name: "Home", Color: "#e69f00"}
+	db.Create(&tag)
+
+	r := gin.New()
+	r.GET("/tags/palette", GetTagPalette())
+	r.POST("/tags", CreateTag(db))
+	r.PUT("/tags/:id", UpdateTag(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tags/palette", nil)
+	r.ServeHTTP(w, req)
+
+	var palette TagPaletteResponse
+	json.Unmarshal(w.Body.Bytes(), &palette)
+	if palette.Name != models.TagPaletteColorblind || !palette.Restricted || len(palette.Colors) != len(models.TagColors()) {
+		t.Errorf("Unexpected palette: %+v", palette)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{"Create with palette color", "POST", "/tags", `{"name": "Work", "color": "#56B4E9"}`, http.StatusCreated},
+		{"Create with off-palette color", "POST", "/tags", `{"name": "Play", "color": "#123456"}`, http.StatusBadRequest},
+		{"Create with generated color", "POST", "/tags", `{"name": "Errands"}`, http.StatusCreated},
+		{"Update with off-palette color", "PUT", "/tags/" + tag.ID, `{"color": "#123456"}`, http.StatusBadRequest},
+		{"Update with palette color", "PUT", "/tags/" + tag.ID, `{"color": "#009e73"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSuggestTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	dev := models.Tag{Name: "dev", Color: "#0000ff"}
+	work := models.Tag{Name: "work", Color: "#ff0000"}
+	home := models.Tag{Name: "home", Color: "#00ff00"}
+	db.Create(&dev)
+	db.Create(&work)
+	db.Create(&home)
+
+	db.Create(&models.Task{Name: "Review PR 12", Tags: []models.Tag{dev, work}})
+	db.Create(&models.Task{Name: "Review PR 15", Tags: []models.Tag{dev}})
+	db.Create(&models.Task{Name: "review design doc", Tags: []models.Tag{dev}})
+	db.Create(&models.Task{Name: "Water plants", Tags: []models.Tag{home}})
+
+	r := gin.New()
+	r.GET("/tags/suggest", SuggestTags(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tags/suggest?name=Review", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var suggestions []TagSuggestion
+	json.Unmarshal(w.Body.Bytes(), &suggestions)
+	if len(suggestions) != 2 {
+		t.Fatalf("Expected 2 suggestions, got %d: %+v", len(suggestions), suggestions)
+	}
+	if suggestions[0].Name != "dev" || suggestions[0].Count != 3 {
+		t.Errorf("Expected 'dev' with count 3 first, got '%s' with count %d", suggestions[0].Name, suggestions[0].Count)
+	}
+	if suggestions[1].Name != "work" {
+		t.Errorf("Expected 'work' second, got '%s'", suggestions[1].Name)
+	}
+}
+
+func TestSuggestTagsRequiresName(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.GET("/tags/suggest", SuggestTags(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tags/suggest", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestCreateTagNotificationsEnabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/tags", CreateTag(db))
+
+	for body, expected := range map[string]bool{
+		`{"name": "default"}`:                               true,
+		`{"name": "muted", "notifications_enabled": false}`: false,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tags", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+
+		var created models.Tag
+		json.Unmarshal(w.Body.Bytes(), &created)
+
+		var stored models.Tag
+		db.First(&stored, "id = ?", created.ID)
+		if created.NotificationsEnabled != expected || stored.NotificationsEnabled != expected {
+			t.Errorf("Expected notifications_enabled %v for %s, got %v (stored %v)", expected, body, created.NotificationsEnabled, stored.NotificationsEnabled)
+		}
+	}
+}
+
+func TestGetTagIntersection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	a := models.Tag{Name: "A", Color: "#ff0000"}
+	b := models.Tag{Name: "B", Color: "#00ff00"}
+	c := models.Tag{Name: "C", Color: "#0000ff"}
+	db.Create(&a)
+	db.Create(&b)
+	db.Create(&c)
+
+	db.Create(&models.Task{Name: "Both", Tags: []models.Tag{a, b}})
+	db.Create(&models.Task{Name: "All three", Tags: []models.Tag{a, b, c}})
+	db.Create(&models.Task{Name: "Only A", Tags: []models.Tag{a}})
+	db.Create(&models.Task{Name: "Only B", Tags: []models.Tag{b}})
+	db.Create(&models.Task{Name: "Deleted", Tags: []models.Tag{a, b}, Deleted: true})
+
+	r := gin.New()
+	r.GET("/tags/intersection", GetTagIntersection(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tags/intersection?ids="+a.ID+","+b.ID+"&breakdown=true", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response TagIntersectionResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Count != 2 {
+		t.Errorf("Expected 2 tasks with both A and B, got %d", response.Count)
+	}
+	if len(response.Breakdown) != 1 || response.Breakdown[0].Name != "C" || response.Breakdown[0].Count != 1 {
+		t.Errorf("Expected adding C to narrow to 1 task, got %+v", response.Breakdown)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tags/intersection", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without ids, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetTagByName(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	db.Create(&models.Tag{Name: "Work", Color: "#ff0000"})
+	db.Create(&models.Tag{Name: "Workout", Color: "#00ff00"})
+
+	r := gin.New()
+	r.GET("/tags/by-name/:name", GetTagByName(db))
+
+	tests := []struct {
+		name           string
+		expectedStatus int
+	}{
+		{"Work", http.StatusOK},
+		{"WORK", http.StatusOK},
+		{"Wor", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/tags/by-name/"+tt.name, nil)
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.expectedStatus {
+			t.Errorf("Expected status %d for %q, got %d", tt.expectedStatus, tt.name, w.Code)
+			continue
+		}
+		if w.Code == http.StatusOK {
+			var tag models.Tag
+			json.Unmarshal(w.Body.Bytes(), &tag)
+			if tag.Name != "Work" {
+				t.Errorf("Expected exact match Work for %q, got %s", tt.name, tag.Name)
+			}
+		}
+	}
+}
+
+func TestMergeTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	dev := models.Tag{Name: "dev", Color: "#111111"}
+	development := models.Tag{Name: "development", Color: "#222222"}
+	db.Create(&dev)
+	db.Create(&development)
+
+	both := models.Task{Name: "Both", Tags: []models.Tag{dev, development}}
+	onlyDev := models.Task{Name: "Only dev", Tags: []models.Tag{dev}}
+	db.Create(&both)
+	db.Create(&onlyDev)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/tags/:id/merge", MergeTag(db, broadcaster))
+
+	merge := func(id, into string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tags/"+id+"/merge", bytes.NewBufferString(`{"into": "`+into+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := merge(dev.ID, dev.ID); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d when merging into itself, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := merge(dev.ID, "missing"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for unknown target, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := merge("missing", development.ID); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown source, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w := merge(dev.ID, development.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var survivor models.Tag
+	json.Unmarshal(w.Body.Bytes(), &survivor)
+	if survivor.ID != development.ID || len(survivor.Tasks) != 2 {
+		t.Errorf("Expected surviving tag with 2 tasks, got %s with %d", survivor.Name, len(survivor.Tasks))
+	}
+
+	// Associations are deduplicated rather than doubled
+	var links int64
+	db.Table("task_tags").Where("task_id = ?", both.ID).Count(&links)
+	if links != 1 {
+		t.Errorf("Expected 1 tag on the task carrying both, got %d", links)
+	}
+
+	if err := db.First(&models.Tag{}, "id = ?", dev.ID).Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("Expected source tag to be deleted, got %v", err)
+	}
+
+	if len(broadcaster.events) != 2 || broadcaster.events[0] != "tag_delete" || broadcaster.events[1] != "task_list_refresh" {
+		t.Errorf("Expected tag_delete and task_list_refresh broadcasts, got %v", broadcaster.events)
+	}
+}
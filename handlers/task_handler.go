@@ -2,81 +2,1449 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"maps"
 	"net/http"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jhoffmann/dailies/metrics"
 	"github.com/jhoffmann/dailies/models"
 	"gorm.io/gorm"
 )
 
-// GetTasks returns a handler function for retrieving all tasks with optional filtering.
+const (
+	// defaultTaskLimit is the number of tasks returned when no limit is given.
+	defaultTaskLimit = 50
+	// maxTaskLimit is the largest number of tasks returned in a single request.
+	maxTaskLimit = 1000
+)
+
+// filterTasks applies the task list filters given as query parameters, such as
+// completed, name and tag_ids, to a task query. It returns an error describing the
+// first invalid parameter.
+func filterTasks(c *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	// Filter by completion status
+	if completed := c.Query("completed"); completed != "" {
+		if comp, err := strconv.ParseBool(completed); err == nil {
+			query = query.Where("completed = ?", comp)
+		}
+	}
+
+	// Filter by archive status, hiding archived tasks unless requested
+	archived := false
+	if value := c.Query("archived"); value != "" {
+		if arch, err := strconv.ParseBool(value); err == nil {
+			archived = arch
+		}
+	}
+	query = query.Where("tasks.archived = ?", archived)
+
+	// Filter by overdue status, allowing for the configured grace period
+	if overdue := c.Query("overdue"); overdue != "" {
+		if over, err := strconv.ParseBool(overdue); err == nil {
+			cutoff := models.OverdueCutoff(time.Now()).Local()
+			if over {
+				query = query.Where("tasks.completed = ? AND tasks.due_date < ?", false, cutoff)
+			} else {
+				query = query.Where("(tasks.completed = ? OR tasks.due_date IS NULL OR tasks.due_date >= ?)", true, cutoff)
+			}
+		}
+	}
+
+	// Optionally hide subtasks so the list keeps its hierarchy
+	if topLevel, _ := strconv.ParseBool(c.Query("top_level_only")); topLevel {
+		query = query.Where("tasks.parent_id IS NULL")
+	}
+
+	// Filter by name (partial matching)
+	if name := c.Query("name"); name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+
+	// Filter by the entry point tasks were created from
+	if source := c.Query("source"); source != "" {
+		if !models.ValidTaskSource(source) {
+			return nil, errors.New("source must be one of web, mcp, cli or api")
+		}
+		query = query.Where("tasks.source = ?", source)
+	}
+
+	// Filter by tag IDs
+	if tagIds := c.Query("tag_ids"); tagIds != "" {
+		ids := strings.Split(tagIds, ",")
+		query = query.Joins("JOIN task_tags ON tasks.id = task_tags.task_id").
+			Where("task_tags.tag_id IN ?", ids).
+			Distinct()
+	}
+
+	// Filter by tag names
+	if tagNames := c.Query("tag"); tagNames != "" {
+		// Tag names are unique regardless of case, so match them case-insensitively
+		var names []string
+		for _, name := range strings.Split(tagNames, ",") {
+			names = append(names, strings.ToLower(strings.TrimSpace(name)))
+		}
+		query = query.Joins("JOIN task_tags ON tasks.id = task_tags.task_id").
+			Joins("JOIN tags ON task_tags.tag_id = tags.id").
+			Where("LOWER(tags.name) IN ? AND tags.deleted_at IS NULL", names).
+			Distinct()
+	}
+
+	// Filter by creation and modification date ranges; from/to bounds are inclusive and
+	// after/before bounds exclusive
+	for _, r := range []struct{ start, end, column, startOp, endOp string }{
+		{"created_from", "created_to", "tasks.created_at", ">=", "<="},
+		{"created_after", "created_before", "tasks.created_at", ">", "<"},
+		{"modified_after", "modified_before", "tasks.updated_at", ">", "<"},
+	} {
+		start, err := parseTimeQuery(c, r.start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTimeQuery(c, r.end)
+		if err != nil {
+			return nil, err
+		}
+		if start != nil && end != nil && end.Before(*start) {
+			return nil, fmt.Errorf("%s must not be after %s", r.start, r.end)
+		}
+		if start != nil {
+			query = query.Where(r.column+" "+r.startOp+" ?", *start)
+		}
+		if end != nil {
+			query = query.Where(r.column+" "+r.endOp+" ?", *end)
+		}
+	}
+
+	// Filter by streak thresholds
+	for _, param := range []struct{ name, condition string }{
+		{"min_streak", "tasks.current_streak >= ?"},
+		{"max_streak", "tasks.current_streak <= ?"},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		streak, err := strconv.Atoi(value)
+		if err != nil || streak < 0 {
+			return nil, errors.New(param.name + " must be a non-negative integer")
+		}
+		query = query.Where(param.condition, streak)
+	}
+
+	return query, nil
+}
+
+// GetTasks returns a handler function for retrieving tasks with optional filtering and
+// pagination. The total number of matching tasks is returned in the X-Total-Count header.
 func GetTasks(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var tasks []models.Task
+		fields, err := parseTaskFields(c.Query("fields"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		tasks := []models.Task{}
 		query := db.Preload("Tags").Preload("Frequency").Where("deleted = ?", false)
 
-		// Filter by completion status
-		if completed := c.Query("completed"); completed != "" {
-			if comp, err := strconv.ParseBool(completed); err == nil {
-				query = query.Where("completed = ?", comp)
+		query, err = filterTasks(c, query)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Pagination
+		limit := defaultTaskLimit
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > maxTaskLimit {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Limit must be between 1 and %d", maxTaskLimit)})
+				return
+			}
+			limit = parsed
+		}
+		offset := 0
+		if value := c.Query("offset"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Offset must be a non-negative integer"})
+				return
+			}
+			offset = parsed
+		}
+
+		// Count all matching tasks before paginating
+		var total int64
+		if err := query.Session(&gorm.Session{}).Model(&models.Task{}).Distinct("tasks.id").Count(&total).Error; err != nil {
+			log.Println("Error counting tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+		c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+		// Sorting, with the creation time and then the ID breaking ties so that the order
+		// is the same on every request
+		sort := c.DefaultQuery("sort", "created_at")
+		switch sort {
+		case "completed":
+			query = query.Order("tasks.completed ASC, tasks.priority ASC")
+		case "priority":
+			query = query.Order("tasks.priority ASC")
+		case "name":
+			query = query.Order("tasks.name")
+		case "position":
+			query = query.Order("tasks.position ASC")
+		}
+		query = query.Order("tasks.created_at ASC, tasks.id ASC")
+
+		if err := query.Limit(limit).Offset(offset).Find(&tasks).Error; err != nil {
+			log.Println("Error fetching tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+
+		if err := attachNoteCounts(db, tasks); err != nil {
+			log.Println("Error counting notes:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+
+		if fields != nil {
+			projected, err := projectFields(tasks, fields)
+			if err != nil {
+				log.Println("Error selecting task fields:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to select task fields"})
+				return
+			}
+			c.JSON(http.StatusOK, projected)
+			return
+		}
+
+		c.JSON(http.StatusOK, tasks)
+	}
+}
+
+// NextTaskResponse represents the single task to focus on next.
+// Task is omitted and AllDone is true when nothing is left to do.
+type NextTaskResponse struct {
+	Task    *models.Task `json:"task,omitempty"`
+	AllDone bool         `json:"all_done"`
+}
+
+// GetNextTask returns a handler function for retrieving the one incomplete task to do next:
+// the highest priority (1 being highest), then the earliest due date, then the oldest.
+// Tasks without a priority or due date sort after those with one.
+func GetNextTask(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tasks []models.Task
+		if err := db.Preload("Tags").Preload("Frequency").
+			Where("deleted = ? AND archived = ? AND completed = ?", false, false, false).
+			Order("priority IS NULL, priority ASC, due_date IS NULL, due_date ASC, created_at ASC").
+			Limit(1).
+			Find(&tasks).Error; err != nil {
+			log.Println("Error fetching next task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch next task"})
+			return
+		}
+
+		if len(tasks) == 0 {
+			c.JSON(http.StatusOK, NextTaskResponse{AllDone: true})
+			return
+		}
+
+		c.JSON(http.StatusOK, NextTaskResponse{Task: &tasks[0]})
+	}
+}
+
+// GetBrokenScheduleTasks returns a handler function for finding tasks whose frequency has
+// a cron expression that fails to parse, so the scheduler can never reset them.
+func GetBrokenScheduleTasks(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var frequencies []models.Frequency
+		if err := db.Find(&frequencies).Error; err != nil {
+			log.Println("Error fetching frequencies:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch frequencies"})
+			return
+		}
+
+		var brokenIDs []string
+		for _, frequency := range frequencies {
+			if err := validateCronExpression(frequency.Period); err != nil {
+				brokenIDs = append(brokenIDs, frequency.ID)
+			}
+		}
+
+		tasks := []models.Task{}
+		if len(brokenIDs) > 0 {
+			if err := db.Preload("Tags").Preload("Frequency").
+				Where("deleted = ? AND frequency_id IN ?", false, brokenIDs).
+				Order("name").
+				Find(&tasks).Error; err != nil {
+				log.Println("Error fetching tasks:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, tasks)
+	}
+}
+
+// GetPendingResetTasks returns a handler function for listing the completed tasks the
+// scheduler would reset on its next check, using the same selection as the scheduler.
+// Tasks with a skipped reset are included, as the check clears their flag instead.
+func GetPendingResetTasks(db *gorm.DB, location *time.Location, timezone string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tasks, err := models.TasksDueForReset(db, time.Now().In(location), timezone)
+		if err != nil {
+			log.Println("Error fetching tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+			return
+		}
+
+		c.JSON(http.StatusOK, tasks)
+	}
+}
+
+// TaskCounts represents aggregate task counts for badges and summaries.
+// Overdue and due today only count incomplete tasks; overdue honors the configured grace period.
+type TaskCounts struct {
+	Incomplete int64 `json:"incomplete"`
+	Completed  int64 `json:"completed"`
+	Overdue    int64 `json:"overdue"`
+	DueToday   int64 `json:"due_today"`
+}
+
+// GetTaskCounts returns a handler function for counting active tasks by state, using
+// the specified timezone to determine the bounds of today.
+func GetTaskCounts(db *gorm.DB, location *time.Location) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now().In(location)
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+		endOfDay := startOfDay.AddDate(0, 0, 1)
+
+		var counts TaskCounts
+		// Times are converted to local time to match how GORM stores them
+		if err := db.Model(&models.Task{}).
+			Select(`COALESCE(SUM(CASE WHEN completed = ? THEN 1 ELSE 0 END), 0) AS incomplete,
+				COALESCE(SUM(CASE WHEN completed = ? THEN 1 ELSE 0 END), 0) AS completed,
+				COALESCE(SUM(CASE WHEN completed = ? AND due_date < ? THEN 1 ELSE 0 END), 0) AS overdue,
+				COALESCE(SUM(CASE WHEN completed = ? AND due_date >= ? AND due_date < ? THEN 1 ELSE 0 END), 0) AS due_today`,
+				false, true, false, models.OverdueCutoff(now).Local(), false, startOfDay.Local(), endOfDay.Local()).
+			Where("deleted = ? AND archived = ?", false, false).
+			Scan(&counts).Error; err != nil {
+			log.Println("Error counting tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+			return
+		}
+
+		c.JSON(http.StatusOK, counts)
+	}
+}
+
+// descendantTaskIDs returns the IDs of all active subtasks below a task, at any depth.
+func descendantTaskIDs(db *gorm.DB, id string) ([]string, error) {
+	var descendants []string
+	parents := []string{id}
+	seen := map[string]bool{id: true}
+
+	for len(parents) > 0 {
+		var children []string
+		if err := db.Model(&models.Task{}).Where("deleted = ? AND parent_id IN ?", false, parents).
+			Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+
+		parents = nil
+		for _, child := range children {
+			if !seen[child] {
+				seen[child] = true
+				descendants = append(descendants, child)
+				parents = append(parents, child)
+			}
+		}
+	}
+	return descendants, nil
+}
+
+// parseTimeQuery parses an optional RFC3339 query parameter. It returns nil when the
+// parameter is absent. Timestamps are converted to local time to match how GORM stores them.
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a valid RFC3339 timestamp", name)
+	}
+
+	parsed = parsed.Local()
+	return &parsed, nil
+}
+
+// parseTaskFields parses a comma-separated list of top-level task JSON fields.
+// It returns nil when no fields are requested and an error for unknown field names.
+func parseTaskFields(value string) (map[string]bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	allowed := jsonFieldNames(reflect.TypeOf(models.Task{}))
+	fields := make(map[string]bool)
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !allowed[field] {
+			return nil, fmt.Errorf("unknown field '%s'", field)
+		}
+		fields[field] = true
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// jsonFieldNames returns the set of JSON field names exposed by a struct type.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// projectFields reduces the JSON representation of an object, or a list of objects,
+// to the selected top-level fields.
+func projectFields(v any, fields map[string]bool) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for _, item := range list {
+			filterKeys(item, fields)
+		}
+		return list, nil
+	}
+
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return nil, err
+	}
+	filterKeys(item, fields)
+	return item, nil
+}
+
+// filterKeys removes every key not present in fields from the map.
+func filterKeys(item map[string]json.RawMessage, fields map[string]bool) {
+	for key := range item {
+		if !fields[key] {
+			delete(item, key)
+		}
+	}
+}
+
+// taskIDParam returns the task ID from the path in canonical form, responding 400 when it
+// is not a valid UUID so that only well-formed IDs without a task are reported as 404.
+func taskIDParam(c *gin.Context) (string, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return "", false
+	}
+	return id.String(), true
+}
+
+// GetTask returns a handler function for retrieving a specific task by ID.
+func GetTask(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fields, err := parseTaskFields(c.Query("fields"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
+		}
+		var task models.Task
+
+		if err := db.Preload("Tags").Preload("Frequency").Preload("Subtasks", func(db *gorm.DB) *gorm.DB {
+			return db.Where("deleted = ?", false).Order("position, created_at")
+		}).Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+				return
+			}
+			log.Println("Error fetching task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+
+		tasks := []models.Task{task}
+		if err := attachNoteCounts(db, tasks); err != nil {
+			log.Println("Error counting notes:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+		task = tasks[0]
+
+		if fields != nil {
+			projected, err := projectFields(task, fields)
+			if err != nil {
+				log.Println("Error selecting task fields:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to select task fields"})
+				return
+			}
+			c.JSON(http.StatusOK, projected)
+			return
+		}
+
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+// taskSourceError is the error returned when a task is created with an unsupported source.
+const taskSourceError = "Source must be one of web, mcp, cli or api"
+
+// CreateTaskRequest represents the request payload for creating a task.
+type CreateTaskRequest struct {
+	Name             string     `json:"name" binding:"required"`
+	Description      *string    `json:"description,omitempty"`
+	Priority         *int       `json:"priority,omitempty"`
+	DueDate          *time.Time `json:"due_date,omitempty"`
+	EstimatedMinutes *int       `json:"estimated_minutes,omitempty"`
+	FrequencyID      *string    `json:"frequency_id,omitempty"`
+	RecurUntil       *time.Time `json:"recur_until,omitempty"`
+	ParentID         *string    `json:"parent_id,omitempty"`
+	TagIDs           []string   `json:"tag_ids,omitempty"`
+	Source           string     `json:"source,omitempty"`
+}
+
+// parseInlineTags splits a task name into the name without #tag tokens and the tag names
+// found in it, e.g. "Buy milk #grocery #urgent" yields "Buy milk" and [grocery urgent].
+// Tag names are deduplicated case-insensitively, keeping the first spelling.
+func parseInlineTags(name string) (string, []string) {
+	var words, tagNames []string
+	seen := make(map[string]bool)
+
+	for _, word := range strings.Fields(name) {
+		if len(word) < 2 || !strings.HasPrefix(word, "#") {
+			words = append(words, word)
+			continue
+		}
+
+		tagName := strings.TrimPrefix(word, "#")
+		if key := strings.ToLower(tagName); !seen[key] {
+			seen[key] = true
+			tagNames = append(tagNames, tagName)
+		}
+	}
+
+	return strings.Join(words, " "), tagNames
+}
+
+// mergeTags appends the extra tags that are not already present in tags.
+func mergeTags(tags []models.Tag, extra []models.Tag) []models.Tag {
+	present := make(map[string]bool)
+	for _, tag := range tags {
+		present[tag.ID] = true
+	}
+	for _, tag := range extra {
+		if !present[tag.ID] {
+			present[tag.ID] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// CreateTask returns a handler function for creating a new task.
+func CreateTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	keys := newIdempotencyCache(idempotencyKeyTTL)
+
+	return func(c *gin.Context) {
+		var req CreateTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// A repeated idempotency key returns the task created by the first request
+		var createdID string
+		key := c.GetHeader(idempotencyKeyHeader)
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Idempotency key must not exceed %d characters", maxIdempotencyKeyLength)})
+			return
+		}
+		if key != "" {
+			taskID, ok := keys.reserve(key, time.Now())
+			if !ok {
+				c.JSON(http.StatusConflict, gin.H{"error": "A request with this idempotency key is still in progress"})
+				return
+			}
+			if taskID != "" {
+				var task models.Task
+				if err := db.Preload("Tags").Preload("Frequency").First(&task, "id = ?", taskID).Error; err != nil {
+					log.Println("Error fetching task:", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+					return
+				}
+				c.Header("Idempotent-Replayed", "true")
+				c.JSON(http.StatusCreated, task)
+				return
+			}
+
+			// Release the key unless the task is created, so a failed request can be retried
+			defer func() {
+				if createdID == "" {
+					keys.release(key)
+				} else {
+					keys.complete(key, createdID, time.Now())
+				}
+			}()
+		}
+
+		// Validate priority range
+		if req.Priority != nil && !models.ValidPriority(*req.Priority) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Priority must be between 1 and %d", models.PriorityLevels())})
+			return
+		}
+		if req.Priority == nil {
+			req.Priority = models.DefaultPriority()
+		}
+
+		// Validate estimate is not negative
+		if req.EstimatedMinutes != nil && *req.EstimatedMinutes < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Estimated minutes must not be negative"})
+			return
+		}
+
+		// Validate source, defaulting to a generic API client
+		if req.Source == "" {
+			req.Source = models.TaskSourceAPI
+		}
+		if !models.ValidTaskSource(req.Source) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": taskSourceError})
+			return
+		}
+
+		// A recurrence end only applies to recurring tasks
+		if req.RecurUntil != nil && req.FrequencyID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Recur until requires a frequency"})
+			return
+		}
+
+		// Validate frequency exists if provided
+		if req.FrequencyID != nil {
+			var frequency models.Frequency
+			if err := db.First(&frequency, "id = ?", *req.FrequencyID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency not found"})
+					return
+				}
+				log.Println("Error validating frequency:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate frequency"})
+				return
+			}
+		}
+
+		// Validate parent task exists if provided, appending the subtask after its siblings
+		position := 0
+		if req.ParentID != nil {
+			var parent models.Task
+			if err := db.Where("deleted = ?", false).First(&parent, "id = ?", *req.ParentID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Parent task not found"})
+					return
+				}
+				log.Println("Error validating parent task:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate parent task"})
+				return
+			}
+
+			if err := db.Model(&models.Task{}).Where("parent_id = ? AND deleted = ?", parent.ID, false).
+				Select("COALESCE(MAX(position) + 1, 0)").Scan(&position).Error; err != nil {
+				log.Println("Error fetching subtask position:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate parent task"})
+				return
+			}
+		}
+
+		// Create task
+		task := models.Task{
+			Name:             req.Name,
+			Description:      req.Description,
+			Priority:         req.Priority,
+			DueDate:          req.DueDate,
+			EstimatedMinutes: req.EstimatedMinutes,
+			FrequencyID:      req.FrequencyID,
+			RecurUntil:       req.RecurUntil,
+			ParentID:         req.ParentID,
+			Position:         position,
+			Source:           req.Source,
+		}
+
+		// Handle tags if provided
+		var tags []models.Tag
+		if len(req.TagIDs) > 0 {
+			if err := db.Find(&tags, "id IN ?", req.TagIDs).Error; err != nil {
+				log.Println("Error fetching tags:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+				return
+			}
+			if len(tags) != len(req.TagIDs) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "One or more tags not found"})
+				return
+			}
+		}
+
+		// Optionally extract inline #tags from the name, creating missing tags
+		if parseTags, _ := strconv.ParseBool(c.Query("parse_tags")); parseTags {
+			name, tagNames := parseInlineTags(task.Name)
+			if name == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Task name cannot consist only of tags"})
+				return
+			}
+			task.Name = name
+
+			inlineTags, err := findOrCreateTagsByName(db, tagNames)
+			if err != nil {
+				log.Println("Error resolving inline tags:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve inline tags"})
+				return
+			}
+			tags = mergeTags(tags, inlineTags)
+		}
+
+		if err := db.Create(&task).Error; err != nil {
+			log.Println("Error creating task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+			return
+		}
+		metrics.TasksCreated.Inc()
+		createdID = task.ID
+
+		// Associate tags
+		if len(tags) > 0 {
+			if err := db.Model(&task).Association("Tags").Append(&tags); err != nil {
+				log.Println("Error associating tags:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to associate tags"})
+				return
+			}
+		}
+
+		// Reload with associations
+		if err := db.Preload("Tags").Preload("Frequency").First(&task, "id = ?", task.ID).Error; err != nil {
+			log.Println("Error reloading task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
+			return
+		}
+
+		// Broadcast WebSocket event
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_create", task)
+			}
+		}
+
+		c.JSON(http.StatusCreated, task)
+	}
+}
+
+// UpdateTaskRequest represents the request payload for updating a task.
+type UpdateTaskRequest struct {
+	Name             *string  `json:"name,omitempty"`
+	Description      *string  `json:"description,omitempty"`
+	Completed        *bool    `json:"completed,omitempty"`
+	Priority         *int     `json:"priority,omitempty"`
+	DueDate          *string  `json:"due_date,omitempty"`
+	EstimatedMinutes *int     `json:"estimated_minutes,omitempty"`
+	FrequencyID      *string  `json:"frequency_id,omitempty"`
+	RecurUntil       *string  `json:"recur_until,omitempty"`
+	Archived         *bool    `json:"archived,omitempty"`
+	TagIDs           []string `json:"tag_ids,omitempty"`
+}
+
+// FieldChange represents the previous and new value of a changed task field.
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// TaskUpdateEvent represents the task_update WebSocket payload sent by UpdateTask and
+// PatchTask: the updated task, plus the previous and new values of its priority,
+// frequency_id and tag_ids when they changed.
+type TaskUpdateEvent struct {
+	models.Task
+	Changes map[string]FieldChange `json:"changes,omitempty"`
+}
+
+// taskState holds the task fields whose changes are reported in a TaskUpdateEvent.
+type taskState struct {
+	Priority    *int
+	FrequencyID *string
+	TagIDs      []string
+}
+
+// changes compares the recorded state with the updated task, including tags only when
+// they were part of the update.
+func (s taskState) changes(task models.Task, compareTags bool) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	if !equalIntPtr(s.Priority, task.Priority) {
+		changes["priority"] = FieldChange{Old: s.Priority, New: task.Priority}
+	}
+	if !equalStringPtr(s.FrequencyID, task.FrequencyID) {
+		changes["frequency_id"] = FieldChange{Old: s.FrequencyID, New: task.FrequencyID}
+	}
+	if compareTags {
+		oldIDs := append([]string{}, s.TagIDs...)
+		newIDs := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			newIDs[i] = tag.ID
+		}
+		slices.Sort(oldIDs)
+		slices.Sort(newIDs)
+		if !slices.Equal(oldIDs, newIDs) {
+			changes["tag_ids"] = FieldChange{Old: oldIDs, New: newIDs}
+		}
+	}
+	return changes
+}
+
+// equalIntPtr reports whether two optional integers are both unset or hold the same value.
+func equalIntPtr(a, b *int) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// equalStringPtr reports whether two optional strings are both unset or hold the same value.
+func equalStringPtr(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// UpdateTask returns a handler function for updating an existing task. Fields that are
+// omitted or null are left unchanged.
+func UpdateTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		updateTask(c, db, req, wsManager...)
+	}
+}
+
+// PatchTask returns a handler function for partially updating an existing task. Only
+// fields present in the body are touched: null clears an optional field, and tag_ids
+// null removes all tags. Name, completed and archived cannot be null.
+func PatchTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body map[string]json.RawMessage
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		req, err := parsePatchTaskRequest(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		updateTask(c, db, req, wsManager...)
+	}
+}
+
+// parsePatchTaskRequest converts a PATCH body into the equivalent update request,
+// mapping null on optional fields to the value UpdateTask treats as removal.
+func parsePatchTaskRequest(body map[string]json.RawMessage) (UpdateTaskRequest, error) {
+	var req UpdateTaskRequest
+	fields := map[string]struct {
+		target any
+		clear  func()
+	}{
+		"name":              {&req.Name, nil},
+		"description":       {&req.Description, func() { req.Description = new(string) }},
+		"completed":         {&req.Completed, nil},
+		"priority":          {&req.Priority, func() { req.Priority = new(int) }},
+		"due_date":          {&req.DueDate, func() { req.DueDate = new(string) }},
+		"estimated_minutes": {&req.EstimatedMinutes, func() { req.EstimatedMinutes = new(int) }},
+		"frequency_id":      {&req.FrequencyID, func() { req.FrequencyID = new(string) }},
+		"recur_until":       {&req.RecurUntil, func() { req.RecurUntil = new(string) }},
+		"archived":          {&req.Archived, nil},
+		"tag_ids":           {&req.TagIDs, func() { req.TagIDs = []string{} }},
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(body)) {
+		field, ok := fields[name]
+		if !ok {
+			return req, fmt.Errorf("%s is not a task field", name)
+		}
+
+		if string(bytes.TrimSpace(body[name])) == "null" {
+			if field.clear == nil {
+				return req, fmt.Errorf("%s cannot be null", name)
+			}
+			field.clear()
+			continue
+		}
+		if err := json.Unmarshal(body[name], field.target); err != nil {
+			return req, fmt.Errorf("%s has an invalid value", name)
+		}
+	}
+	return req, nil
+}
+
+// updateTask applies an update request to the task identified by the id path parameter,
+// responding with the updated task.
+func updateTask(c *gin.Context, db *gorm.DB, req UpdateTaskRequest, wsManager ...any) {
+	id, ok := taskIDParam(c)
+	if !ok {
+		return
+	}
+
+	var task models.Task
+	if err := db.Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		log.Println("Error fetching task:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+		return
+	}
+
+	// A name left out of the request is unchanged, but a blank one is rejected
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task name must not be empty"})
+		return
+	}
+
+	// Handle priority: 0 means remove, 1 to the configured maximum means set, anything else is invalid
+	removePriority := false
+	if req.Priority != nil {
+		if *req.Priority == 0 {
+			removePriority = true
+		} else if !models.ValidPriority(*req.Priority) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Priority must be between 1 and %d", models.PriorityLevels())})
+			return
+		}
+	}
+
+	// Handle estimate: 0 means remove, negative values are invalid
+	if req.EstimatedMinutes != nil && *req.EstimatedMinutes < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estimated minutes must not be negative"})
+		return
+	}
+
+	// Handle due date: empty string means remove, otherwise it must be RFC3339
+	var dueDate *time.Time
+	if req.DueDate != nil && *req.DueDate != "" {
+		parsed, err := time.Parse(time.RFC3339, *req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Due date must be a valid RFC3339 timestamp"})
+			return
+		}
+		dueDate = &parsed
+	}
+
+	// Handle recurrence end: empty string means remove, otherwise it must be RFC3339
+	var recurUntil *time.Time
+	if req.RecurUntil != nil && *req.RecurUntil != "" {
+		parsed, err := time.Parse(time.RFC3339, *req.RecurUntil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Recur until must be a valid RFC3339 timestamp"})
+			return
+		}
+		recurUntil = &parsed
+	}
+
+	// Handle empty string frequency ID (treat as removal)
+	removeFrequency := false
+	if req.FrequencyID != nil && *req.FrequencyID == "" {
+		removeFrequency = true
+	}
+
+	// Validate frequency exists if provided and not empty
+	if req.FrequencyID != nil && *req.FrequencyID != "" {
+		var frequency models.Frequency
+		if err := db.First(&frequency, "id = ?", *req.FrequencyID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency not found"})
+				return
+			}
+			log.Println("Error validating frequency:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate frequency"})
+			return
+		}
+	}
+
+	// Record the values reported as changed in the WebSocket event, copied since
+	// updating writes through the task's pointers
+	var previous taskState
+	if task.Priority != nil {
+		priority := *task.Priority
+		previous.Priority = &priority
+	}
+	if task.FrequencyID != nil {
+		frequencyID := *task.FrequencyID
+		previous.FrequencyID = &frequencyID
+	}
+	if req.TagIDs != nil {
+		if err := db.Table("task_tags").Where("task_id = ?", task.ID).Pluck("tag_id", &previous.TagIDs).Error; err != nil {
+			log.Println("Error fetching task tags:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+	}
+
+	// Update fields
+	updates := make(map[string]any)
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Completed != nil {
+		updates["completed"] = *req.Completed
+	}
+	// Handle priority: set to nil to remove, or set to value
+	if removePriority {
+		updates["priority"] = nil
+	} else if req.Priority != nil {
+		updates["priority"] = *req.Priority
+	}
+	// Handle estimated_minutes: set to nil to remove, or set to value
+	if req.EstimatedMinutes != nil {
+		if *req.EstimatedMinutes == 0 {
+			updates["estimated_minutes"] = nil
+		} else {
+			updates["estimated_minutes"] = *req.EstimatedMinutes
+		}
+	}
+	// Handle due_date: set to nil to remove, or set to parsed value
+	if req.DueDate != nil {
+		if dueDate == nil {
+			updates["due_date"] = nil
+		} else {
+			updates["due_date"] = *dueDate
+		}
+	}
+	// Handle recur_until: set to nil to remove, or set to parsed value
+	if req.RecurUntil != nil {
+		if recurUntil == nil {
+			updates["recur_until"] = nil
+		} else {
+			updates["recur_until"] = *recurUntil
+		}
+	}
+	if req.Archived != nil {
+		updates["archived"] = *req.Archived
+	}
+	// Handle frequency_id: set to nil to remove, or set to ID value
+	if removeFrequency {
+		updates["frequency_id"] = nil
+	} else if req.FrequencyID != nil {
+		updates["frequency_id"] = *req.FrequencyID
+	}
+
+	// Updating the model overwrites its fields, so note whether this completes the task first
+	completing := req.Completed != nil && *req.Completed && !task.Completed
+	if len(updates) > 0 {
+		if err := db.Model(&task).Updates(updates).Error; err != nil {
+			log.Println("Error updating task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+			return
+		}
+	}
+	if completing {
+		metrics.TasksCompleted.Inc()
+	}
+
+	// Optionally complete all subtasks along with their parent
+	if cascade, _ := strconv.ParseBool(c.Query("cascade")); cascade && req.Completed != nil && *req.Completed {
+		descendants, err := descendantTaskIDs(db, task.ID)
+		if err == nil && len(descendants) > 0 {
+			update := db.Model(&models.Task{}).Where("id IN ? AND completed = ?", descendants, false).Update("completed", true)
+			err = update.Error
+			metrics.TasksCompleted.Add(float64(update.RowsAffected))
+		}
+		if err != nil {
+			log.Println("Error completing subtasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete subtasks"})
+			return
+		}
+	}
+
+	// Handle tag associations
+	if req.TagIDs != nil {
+		var tags []models.Tag
+		if len(req.TagIDs) > 0 {
+			if err := db.Find(&tags, "id IN ?", req.TagIDs).Error; err != nil {
+				log.Println("Error fetching tags:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+				return
+			}
+			if len(tags) != len(req.TagIDs) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "One or more tags not found"})
+				return
+			}
+		}
+
+		// Replace all tag associations
+		if err := db.Model(&task).Association("Tags").Replace(&tags); err != nil {
+			log.Println("Error updating tag associations:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag associations"})
+			return
+		}
+	}
+
+	// Reload with associations
+	if err := db.Preload("Tags").Preload("Frequency").First(&task, "id = ?", task.ID).Error; err != nil {
+		log.Println("Error reloading task:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
+		return
+	}
+
+	// Broadcast WebSocket event
+	if len(wsManager) > 0 && wsManager[0] != nil {
+		if ws, ok := wsManager[0].(interface {
+			Broadcast(eventType any, data any)
+		}); ok {
+			ws.Broadcast("task_update", TaskUpdateEvent{Task: task, Changes: previous.changes(task, req.TagIDs != nil)})
+		}
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// DeleteTask returns a handler function for soft deleting a task.
+func DeleteTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
+		}
+
+		var task models.Task
+		if err := db.Preload("Tags").Preload("Frequency").Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+				return
+			}
+			log.Println("Error fetching task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+
+		// Soft delete by setting the deleted flag and recording when it happened.
+		// Subtasks are deleted along with the task when cascading, otherwise orphaned.
+		now := time.Now()
+		cascade, _ := strconv.ParseBool(c.Query("cascade"))
+		deleted := 1
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if cascade {
+				descendants, err := descendantTaskIDs(tx, task.ID)
+				if err != nil {
+					return err
+				}
+				if len(descendants) > 0 {
+					if err := tx.Model(&models.Task{}).Where("id IN ?", descendants).
+						Updates(map[string]any{"deleted": true, "deleted_at": now}).Error; err != nil {
+						return err
+					}
+					deleted += len(descendants)
+				}
+			} else if err := tx.Model(&models.Task{}).Where("parent_id = ?", task.ID).Update("parent_id", nil).Error; err != nil {
+				return err
+			}
+			return tx.Model(&task).Updates(map[string]any{"deleted": true, "deleted_at": now}).Error
+		})
+		if err != nil {
+			log.Println("Error soft deleting task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
+			return
+		}
+		metrics.TasksDeleted.Add(float64(deleted))
+
+		// Update the task object for the WebSocket event
+		task.Deleted = true
+		task.DeletedAt = &now
+
+		// Broadcast WebSocket event
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_delete", task)
+			}
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// RestoreTask returns a handler function for restoring a soft deleted task from the trash.
+func RestoreTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
+		}
+
+		var task models.Task
+		if err := db.Where("deleted = ?", true).First(&task, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Deleted task not found"})
+				return
+			}
+			log.Println("Error fetching task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+
+		if err := db.Model(&task).Updates(map[string]any{"deleted": false, "deleted_at": nil}).Error; err != nil {
+			log.Println("Error restoring task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore task"})
+			return
+		}
+
+		// Reload with associations
+		if err := db.Preload("Tags").Preload("Frequency").First(&task, "id = ?", task.ID).Error; err != nil {
+			log.Println("Error reloading task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
+			return
+		}
+
+		// Broadcast WebSocket event
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_create", task)
+			}
+		}
+
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+// SkipNextReset returns a handler function that marks a recurring task to skip its next
+// scheduled reset, leaving it completed for one more period.
+func SkipNextReset(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
+		}
+
+		var task models.Task
+		if err := db.Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+				return
+			}
+			log.Println("Error fetching task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+
+		if task.FrequencyID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Task has no frequency"})
+			return
+		}
+
+		if err := db.Model(&task).UpdateColumn("skip_next_reset", true).Error; err != nil {
+			log.Println("Error updating task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+			return
+		}
+
+		// Reload with associations
+		if err := db.Preload("Tags").Preload("Frequency").First(&task, "id = ?", task.ID).Error; err != nil {
+			log.Println("Error reloading task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
+			return
+		}
+
+		// Broadcast WebSocket event
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_update", task)
+			}
+		}
+
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+// copyTask returns a new, incomplete task carrying the attributes of source, under the
+// same parent and source. Identity, completion, streak, tags and subtasks are not copied.
+func copyTask(source models.Task) models.Task {
+	return models.Task{
+		Name:             source.Name,
+		Description:      source.Description,
+		Priority:         source.Priority,
+		DueDate:          source.DueDate,
+		EstimatedMinutes: source.EstimatedMinutes,
+		FrequencyID:      source.FrequencyID,
+		RecurUntil:       source.RecurUntil,
+		ParentID:         source.ParentID,
+		Source:           source.Source,
+	}
+}
+
+// FanOutTaskRequest represents the request payload for fanning a task out across tags.
+type FanOutTaskRequest struct {
+	TagIDs []string `json:"tag_ids" binding:"required,min=1"`
+}
+
+// FanOutTask returns a handler function that creates one copy of a task per requested
+// tag, each carrying only that tag, in a single transaction.
+func FanOutTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
+		}
+		var req FanOutTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var source models.Task
+		if err := db.Where("deleted = ?", false).First(&source, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+				return
+			}
+			log.Println("Error fetching task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			return
+		}
+
+		var tags []models.Tag
+		if err := db.Find(&tags, "id IN ?", req.TagIDs).Error; err != nil {
+			log.Println("Error fetching tags:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+			return
+		}
+		tagsByID := make(map[string]models.Tag)
+		for _, tag := range tags {
+			tagsByID[tag.ID] = tag
+		}
+
+		var ids []string
+		seen := make(map[string]bool)
+		for _, tagID := range req.TagIDs {
+			if _, ok := tagsByID[tagID]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "One or more tags not found"})
+				return
+			}
+			if seen[tagID] {
+				continue
+			}
+			seen[tagID] = true
+			ids = append(ids, tagID)
+		}
+
+		created := make([]models.Task, 0, len(ids))
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, tagID := range ids {
+				task := copyTask(source)
+				task.Tags = []models.Tag{tagsByID[tagID]}
+				if err := tx.Create(&task).Error; err != nil {
+					return err
+				}
+				created = append(created, task)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("Error fanning out task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tasks"})
+			return
+		}
+		metrics.TasksCreated.Add(float64(len(created)))
+
+		// Reload with associations
+		for i := range created {
+			if err := db.Preload("Tags").Preload("Frequency").First(&created[i], "id = ?", created[i].ID).Error; err != nil {
+				log.Println("Error reloading task:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
+				return
 			}
 		}
 
-		// Filter by name (partial matching)
-		if name := c.Query("name"); name != "" {
-			query = query.Where("name LIKE ?", "%"+name+"%")
+		// Broadcast WebSocket events
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				for _, task := range created {
+					ws.Broadcast("task_create", task)
+				}
+			}
 		}
 
-		// Filter by tag IDs
-		if tagIds := c.Query("tag_ids"); tagIds != "" {
-			ids := strings.Split(tagIds, ",")
-			query = query.Joins("JOIN task_tags ON tasks.id = task_tags.task_id").
-				Where("task_tags.tag_id IN ?", ids).
-				Distinct()
-		}
+		c.JSON(http.StatusCreated, created)
+	}
+}
 
-		// Filter by tag names
-		if tagNames := c.Query("tag"); tagNames != "" {
-			names := strings.Split(tagNames, ",")
-			query = query.Joins("JOIN task_tags ON tasks.id = task_tags.task_id").
-				Joins("JOIN tags ON task_tags.tag_id = tags.id").
-				Where("tags.name IN ?", names).
-				Distinct()
-		}
+// SetTaskTagsRequest represents the request payload for replacing a task's tags by name.
+type SetTaskTagsRequest struct {
+	TagNames []string `json:"tag_names" binding:"required"`
+}
 
-		// Sorting
-		sort := c.DefaultQuery("sort", "created_at")
-		switch sort {
-		case "completed":
-			query = query.Order("tasks.completed ASC, tasks.priority ASC")
-		case "priority":
-			query = query.Order("tasks.priority ASC")
-		case "name":
-			query = query.Order("tasks.name")
-		default:
-			query = query.Order("tasks.created_at ASC")
+// SetTaskTags returns a handler function that replaces all tags of a task with the named
+// tags, creating tags that do not exist yet. An empty list removes all tags.
+func SetTaskTags(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
 		}
-
-		if err := query.Find(&tasks).Error; err != nil {
-			log.Println("Error fetching tasks:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		var req SetTaskTagsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, tasks)
-	}
-}
-
-// GetTask returns a handler function for retrieving a specific task by ID.
-func GetTask(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		id := c.Param("id")
 		var task models.Task
-
-		if err := db.Preload("Tags").Preload("Frequency").Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
+		if err := db.Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 				return
@@ -86,31 +1454,85 @@ func GetTask(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		err := db.Transaction(func(tx *gorm.DB) error {
+			tags, err := findOrCreateTagsByName(tx, req.TagNames)
+			if err != nil {
+				return err
+			}
+			tags = mergeTags(nil, tags)
+			return tx.Model(&task).Association("Tags").Replace(&tags)
+		})
+		if err != nil {
+			log.Println("Error updating tag associations:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag associations"})
+			return
+		}
+
+		// Reload with associations
+		if err := db.Preload("Tags").Preload("Frequency").First(&task, "id = ?", task.ID).Error; err != nil {
+			log.Println("Error reloading task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
+			return
+		}
+
+		// Broadcast WebSocket event
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_update", task)
+			}
+		}
+
 		c.JSON(http.StatusOK, task)
 	}
 }
 
-// CreateTaskRequest represents the request payload for creating a task.
-type CreateTaskRequest struct {
-	Name        string   `json:"name" binding:"required"`
-	Description *string  `json:"description,omitempty"`
-	Priority    *int     `json:"priority,omitempty"`
+// BulkCreateTasksRequest represents the request payload for creating several tasks that
+// share a frequency, tags and priority.
+type BulkCreateTasksRequest struct {
+	Names       []string `json:"names" binding:"required,min=1"`
 	FrequencyID *string  `json:"frequency_id,omitempty"`
 	TagIDs      []string `json:"tag_ids,omitempty"`
+	Priority    *int     `json:"priority,omitempty"`
+	Source      string   `json:"source,omitempty"`
 }
 
-// CreateTask returns a handler function for creating a new task.
-func CreateTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+// BulkCreateTasks returns a handler function that creates one task per name with the
+// shared attributes in a single transaction.
+func BulkCreateTasks(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req CreateTaskRequest
+		var req BulkCreateTasksRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
+		var names []string
+		for _, name := range req.Names {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Task names must not be empty"})
+				return
+			}
+			names = append(names, name)
+		}
+
 		// Validate priority range
-		if req.Priority != nil && (*req.Priority < 1 || *req.Priority > 5) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Priority must be between 1 and 5"})
+		if req.Priority != nil && !models.ValidPriority(*req.Priority) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Priority must be between 1 and %d", models.PriorityLevels())})
+			return
+		}
+		if req.Priority == nil {
+			req.Priority = models.DefaultPriority()
+		}
+
+		// Validate source, defaulting to a generic API client
+		if req.Source == "" {
+			req.Source = models.TaskSourceAPI
+		}
+		if !models.ValidTaskSource(req.Source) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": taskSourceError})
 			return
 		}
 
@@ -128,15 +1550,7 @@ func CreateTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 			}
 		}
 
-		// Create task
-		task := models.Task{
-			Name:        req.Name,
-			Description: req.Description,
-			Priority:    req.Priority,
-			FrequencyID: req.FrequencyID,
-		}
-
-		// Handle tags if provided
+		// Validate tags exist if provided
 		var tags []models.Tag
 		if len(req.TagIDs) > 0 {
 			if err := db.Find(&tags, "id IN ?", req.TagIDs).Error; err != nil {
@@ -150,185 +1564,366 @@ func CreateTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 			}
 		}
 
-		if err := db.Create(&task).Error; err != nil {
-			log.Println("Error creating task:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		created := make([]models.Task, 0, len(names))
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, name := range names {
+				task := models.Task{
+					Name:        name,
+					Priority:    req.Priority,
+					FrequencyID: req.FrequencyID,
+					Source:      req.Source,
+					Tags:        tags,
+				}
+				if err := tx.Create(&task).Error; err != nil {
+					return err
+				}
+				created = append(created, task)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("Error creating tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tasks"})
 			return
 		}
+		metrics.TasksCreated.Add(float64(len(created)))
 
-		// Associate tags
-		if len(tags) > 0 {
-			if err := db.Model(&task).Association("Tags").Append(&tags); err != nil {
-				log.Println("Error associating tags:", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to associate tags"})
+		// Reload with associations
+		for i := range created {
+			if err := db.Preload("Tags").Preload("Frequency").First(&created[i], "id = ?", created[i].ID).Error; err != nil {
+				log.Println("Error reloading task:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
 				return
 			}
 		}
 
-		// Reload with associations
-		if err := db.Preload("Tags").Preload("Frequency").First(&task, "id = ?", task.ID).Error; err != nil {
-			log.Println("Error reloading task:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
-			return
-		}
-
-		// Broadcast WebSocket event
+		// Broadcast a single WebSocket event for the whole batch
 		if len(wsManager) > 0 && wsManager[0] != nil {
 			if ws, ok := wsManager[0].(interface {
 				Broadcast(eventType any, data any)
 			}); ok {
-				ws.Broadcast("task_create", task)
+				ws.Broadcast("task_list_refresh", created)
 			}
 		}
 
-		c.JSON(http.StatusCreated, task)
+		c.JSON(http.StatusCreated, created)
 	}
 }
 
-// UpdateTaskRequest represents the request payload for updating a task.
-type UpdateTaskRequest struct {
-	Name        *string  `json:"name,omitempty"`
-	Description *string  `json:"description,omitempty"`
-	Completed   *bool    `json:"completed,omitempty"`
-	Priority    *int     `json:"priority,omitempty"`
-	FrequencyID *string  `json:"frequency_id,omitempty"`
-	TagIDs      []string `json:"tag_ids,omitempty"`
+// batchTaskError reports an invalid task payload in a batch request by its index.
+type batchTaskError struct {
+	index   int
+	message string
 }
 
-// UpdateTask returns a handler function for updating an existing task.
-func UpdateTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+// Error implements the error interface.
+func (e *batchTaskError) Error() string {
+	return fmt.Sprintf("Task at index %d: %s", e.index, e.message)
+}
+
+// newBatchTask validates a single payload of a batch request and builds the task to
+// create, returning a *batchTaskError when the payload is invalid.
+func newBatchTask(tx *gorm.DB, index int, req CreateTaskRequest) (models.Task, error) {
+	invalid := func(message string) (models.Task, error) {
+		return models.Task{}, &batchTaskError{index: index, message: message}
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		return invalid("Task name must not be empty")
+	}
+	if req.Priority != nil && !models.ValidPriority(*req.Priority) {
+		return invalid(fmt.Sprintf("Priority must be between 1 and %d", models.PriorityLevels()))
+	}
+	if req.Priority == nil {
+		req.Priority = models.DefaultPriority()
+	}
+	if req.EstimatedMinutes != nil && *req.EstimatedMinutes < 0 {
+		return invalid("Estimated minutes must not be negative")
+	}
+	if req.Source == "" {
+		req.Source = models.TaskSourceAPI
+	}
+	if !models.ValidTaskSource(req.Source) {
+		return invalid(taskSourceError)
+	}
+	if req.RecurUntil != nil && req.FrequencyID == nil {
+		return invalid("Recur until requires a frequency")
+	}
+
+	if req.FrequencyID != nil {
+		var frequency models.Frequency
+		if err := tx.First(&frequency, "id = ?", *req.FrequencyID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return invalid("Frequency not found")
+			}
+			return models.Task{}, err
+		}
+	}
+
+	// Subtasks are appended after their siblings, including those created earlier in the batch
+	position := 0
+	if req.ParentID != nil {
+		var parent models.Task
+		if err := tx.Where("deleted = ?", false).First(&parent, "id = ?", *req.ParentID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return invalid("Parent task not found")
+			}
+			return models.Task{}, err
+		}
+		if err := tx.Model(&models.Task{}).Where("parent_id = ? AND deleted = ?", parent.ID, false).
+			Select("COALESCE(MAX(position) + 1, 0)").Scan(&position).Error; err != nil {
+			return models.Task{}, err
+		}
+	}
+
+	var tags []models.Tag
+	if len(req.TagIDs) > 0 {
+		if err := tx.Find(&tags, "id IN ?", req.TagIDs).Error; err != nil {
+			return models.Task{}, err
+		}
+		if len(tags) != len(req.TagIDs) {
+			return invalid("One or more tags not found")
+		}
+	}
+
+	return models.Task{
+		Name:             strings.TrimSpace(req.Name),
+		Description:      req.Description,
+		Priority:         req.Priority,
+		DueDate:          req.DueDate,
+		EstimatedMinutes: req.EstimatedMinutes,
+		FrequencyID:      req.FrequencyID,
+		RecurUntil:       req.RecurUntil,
+		ParentID:         req.ParentID,
+		Position:         position,
+		Source:           req.Source,
+		Tags:             tags,
+	}, nil
+}
+
+// BatchCreateTasks returns a handler function that creates tasks from an array of task
+// payloads in a single transaction. Every payload is validated like CreateTask; if any is
+// invalid nothing is created and the error names the index of the offending payload.
+func BatchCreateTasks(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		var req UpdateTaskRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
+		var reqs []CreateTaskRequest
+		if err := c.ShouldBindJSON(&reqs); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if len(reqs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "At least one task is required"})
+			return
+		}
 
-		var task models.Task
-		if err := db.Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		created := make([]models.Task, 0, len(reqs))
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for i, req := range reqs {
+				task, err := newBatchTask(tx, i, req)
+				if err != nil {
+					return err
+				}
+				if err := tx.Create(&task).Error; err != nil {
+					return err
+				}
+				created = append(created, task)
+			}
+			return nil
+		})
+		if err != nil {
+			if batchErr, ok := err.(*batchTaskError); ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": batchErr.Error()})
 				return
 			}
-			log.Println("Error fetching task:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+			log.Println("Error creating tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tasks"})
 			return
 		}
+		metrics.TasksCreated.Add(float64(len(created)))
 
-		// Handle priority: 0 means remove, 1-5 means set, anything else is invalid
-		removePriority := false
-		if req.Priority != nil {
-			if *req.Priority == 0 {
-				removePriority = true
-			} else if *req.Priority < 1 || *req.Priority > 5 {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Priority must be between 1 and 5"})
+		// Reload with associations
+		for i := range created {
+			if err := db.Preload("Tags").Preload("Frequency").First(&created[i], "id = ?", created[i].ID).Error; err != nil {
+				log.Println("Error reloading task:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
 				return
 			}
 		}
 
-		// Handle empty string frequency ID (treat as removal)
-		removeFrequency := false
-		if req.FrequencyID != nil && *req.FrequencyID == "" {
-			removeFrequency = true
+		// Broadcast a single WebSocket event for the whole batch
+		if len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_list_refresh", created)
+			}
 		}
 
-		// Validate frequency exists if provided and not empty
-		if req.FrequencyID != nil && *req.FrequencyID != "" {
-			var frequency models.Frequency
-			if err := db.First(&frequency, "id = ?", *req.FrequencyID).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency not found"})
-					return
+		c.JSON(http.StatusCreated, created)
+	}
+}
+
+// BulkCompleteTasksRequest represents the request payload for completing several tasks at once.
+type BulkCompleteTasksRequest struct {
+	TaskIDs   []string `json:"task_ids" binding:"required,min=1"`
+	Completed *bool    `json:"completed" binding:"required"`
+}
+
+// BulkCompleteResult reports the outcome of updating a single task in a bulk request.
+type BulkCompleteResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkCompleteTasks returns a handler function that sets the completion status of several
+// tasks in a single transaction, reporting success or failure for each ID.
+func BulkCompleteTasks(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BulkCompleteTasksRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		results := make([]BulkCompleteResult, 0, len(req.TaskIDs))
+		updated := 0
+		var newlyCompleted int64
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if *req.Completed {
+				if err := tx.Model(&models.Task{}).
+					Where("id IN ? AND deleted = ? AND completed = ?", req.TaskIDs, false, false).
+					Count(&newlyCompleted).Error; err != nil {
+					return err
 				}
-				log.Println("Error validating frequency:", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate frequency"})
-				return
 			}
+			for _, id := range req.TaskIDs {
+				update := tx.Model(&models.Task{}).
+					Where("id = ? AND deleted = ?", id, false).
+					Update("completed", *req.Completed)
+				if update.Error != nil {
+					return update.Error
+				}
+				if update.RowsAffected == 0 {
+					results = append(results, BulkCompleteResult{ID: id, Error: "Task not found"})
+					continue
+				}
+				results = append(results, BulkCompleteResult{ID: id, Success: true})
+				updated++
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("Error updating tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tasks"})
+			return
 		}
+		metrics.TasksCompleted.Add(float64(newlyCompleted))
 
-		// Update fields
-		updates := make(map[string]any)
-		if req.Name != nil {
-			updates["name"] = *req.Name
-		}
-		if req.Description != nil {
-			updates["description"] = *req.Description
-		}
-		if req.Completed != nil {
-			updates["completed"] = *req.Completed
-		}
-		// Handle priority: set to nil to remove, or set to value (1-5)
-		if removePriority {
-			updates["priority"] = nil
-		} else if req.Priority != nil {
-			updates["priority"] = *req.Priority
+		// Broadcast a single WebSocket event for the whole batch
+		if updated > 0 && len(wsManager) > 0 && wsManager[0] != nil {
+			if ws, ok := wsManager[0].(interface {
+				Broadcast(eventType any, data any)
+			}); ok {
+				ws.Broadcast("task_list_refresh", results)
+			}
 		}
-		// Handle frequency_id: set to nil to remove, or set to ID value
-		if removeFrequency {
-			updates["frequency_id"] = nil
-		} else if req.FrequencyID != nil {
-			updates["frequency_id"] = *req.FrequencyID
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// ReorderTasksRequest represents the request payload for manually ordering tasks.
+type ReorderTasksRequest struct {
+	TaskIDs []string `json:"task_ids" binding:"required,min=1"`
+}
+
+// ReorderTasks returns a handler function that assigns positions to tasks in the order
+// given, for use with ?sort=position. Tasks not listed keep their current positions.
+func ReorderTasks(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ReorderTasksRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 
-		if len(updates) > 0 {
-			if err := db.Model(&task).Updates(updates).Error; err != nil {
-				log.Println("Error updating task:", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		listed := make(map[string]bool, len(req.TaskIDs))
+		for _, id := range req.TaskIDs {
+			if listed[id] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Task " + id + " is listed more than once"})
 				return
 			}
+			listed[id] = true
 		}
 
-		// Handle tag associations
-		if req.TagIDs != nil {
-			var tags []models.Tag
-			if len(req.TagIDs) > 0 {
-				if err := db.Find(&tags, "id IN ?", req.TagIDs).Error; err != nil {
-					log.Println("Error fetching tags:", err)
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
-					return
-				}
-				if len(tags) != len(req.TagIDs) {
-					c.JSON(http.StatusBadRequest, gin.H{"error": "One or more tags not found"})
-					return
-				}
-			}
+		var found int64
+		if err := db.Model(&models.Task{}).Where("id IN ? AND deleted = ?", req.TaskIDs, false).Count(&found).Error; err != nil {
+			log.Println("Error validating tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate tasks"})
+			return
+		}
+		if found != int64(len(req.TaskIDs)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "One or more tasks not found"})
+			return
+		}
 
-			// Replace all tag associations
-			if err := db.Model(&task).Association("Tags").Replace(&tags); err != nil {
-				log.Println("Error updating tag associations:", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag associations"})
-				return
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			for position, id := range req.TaskIDs {
+				if err := tx.Model(&models.Task{}).Where("id = ?", id).UpdateColumn("position", position).Error; err != nil {
+					return err
+				}
 			}
+			return nil
+		}); err != nil {
+			log.Println("Error reordering tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder tasks"})
+			return
 		}
 
-		// Reload with associations
-		if err := db.Preload("Tags").Preload("Frequency").First(&task, "id = ?", task.ID).Error; err != nil {
-			log.Println("Error reloading task:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
+		tasks := []models.Task{}
+		if err := db.Preload("Tags").Preload("Frequency").Where("id IN ?", req.TaskIDs).
+			Order("position ASC").Find(&tasks).Error; err != nil {
+			log.Println("Error reloading tasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload tasks"})
 			return
 		}
 
-		// Broadcast WebSocket event
+		// Broadcast a single WebSocket event for the whole reorder
 		if len(wsManager) > 0 && wsManager[0] != nil {
 			if ws, ok := wsManager[0].(interface {
 				Broadcast(eventType any, data any)
 			}); ok {
-				ws.Broadcast("task_update", task)
+				ws.Broadcast("task_list_refresh", req.TaskIDs)
 			}
 		}
 
-		c.JSON(http.StatusOK, task)
+		c.JSON(http.StatusOK, tasks)
 	}
 }
 
-// DeleteTask returns a handler function for soft deleting a task.
-func DeleteTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
+// ReorderSubtasksRequest represents the request payload for reordering a task's subtasks.
+type ReorderSubtasksRequest struct {
+	SubtaskIDs []string `json:"subtask_ids" binding:"required"`
+}
+
+// ReorderSubtasks returns a handler function that sets the order of a task's subtasks.
+// Subtasks omitted from the request keep their relative order after the listed ones.
+func ReorderSubtasks(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
+		id, ok := taskIDParam(c)
+		if !ok {
+			return
+		}
+
+		var req ReorderSubtasksRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
 		var task models.Task
-		if err := db.Preload("Tags").Preload("Frequency").Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
+		if err := db.Where("deleted = ?", false).First(&task, "id = ?", id).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 				return
@@ -338,25 +1933,70 @@ func DeleteTask(db *gorm.DB, wsManager ...any) gin.HandlerFunc {
 			return
 		}
 
-		// Soft delete by setting the deleted flag
-		if err := db.Model(&task).Update("deleted", true).Error; err != nil {
-			log.Println("Error soft deleting task:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
+		var subtasks []models.Task
+		if err := db.Where("parent_id = ? AND deleted = ?", task.ID, false).
+			Order("position, created_at").Find(&subtasks).Error; err != nil {
+			log.Println("Error fetching subtasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subtasks"})
 			return
 		}
 
-		// Update the task object for the WebSocket event
-		task.Deleted = true
+		existing := make(map[string]bool, len(subtasks))
+		for _, subtask := range subtasks {
+			existing[subtask.ID] = true
+		}
 
-		// Broadcast WebSocket event
+		ordered := make([]string, 0, len(subtasks))
+		listed := make(map[string]bool, len(req.SubtaskIDs))
+		for _, subtaskID := range req.SubtaskIDs {
+			if !existing[subtaskID] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Subtask " + subtaskID + " does not belong to this task"})
+				return
+			}
+			if listed[subtaskID] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Subtask " + subtaskID + " is listed more than once"})
+				return
+			}
+			listed[subtaskID] = true
+			ordered = append(ordered, subtaskID)
+		}
+		for _, subtask := range subtasks {
+			if !listed[subtask.ID] {
+				ordered = append(ordered, subtask.ID)
+			}
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			for position, subtaskID := range ordered {
+				if err := tx.Model(&models.Task{}).Where("id = ?", subtaskID).UpdateColumn("position", position).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			log.Println("Error reordering subtasks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder subtasks"})
+			return
+		}
+
+		// Reload task with its subtasks in their new order
+		if err := db.Preload("Tags").Preload("Frequency").Preload("Subtasks", func(db *gorm.DB) *gorm.DB {
+			return db.Where("deleted = ?", false).Order("position, created_at")
+		}).First(&task, "id = ?", task.ID).Error; err != nil {
+			log.Println("Error reloading task:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload task"})
+			return
+		}
+
+		// Broadcast WebSocket event if manager is provided
 		if len(wsManager) > 0 && wsManager[0] != nil {
 			if ws, ok := wsManager[0].(interface {
 				Broadcast(eventType any, data any)
 			}); ok {
-				ws.Broadcast("task_delete", task)
+				ws.Broadcast("task_update", task)
 			}
 		}
 
-		c.JSON(http.StatusNoContent, nil)
+		c.JSON(http.StatusOK, task)
 	}
 }
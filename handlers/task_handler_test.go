@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/metrics"
 	"github.com/jhoffmann/dailies/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -21,7 +25,7 @@ func setupTestHandlerDB(t *testing.T) *gorm.DB {
 	}
 
 	// Auto migrate tables
-	err = db.AutoMigrate(&models.Task{}, &models.Tag{}, &models.Frequency{})
+	err = db.AutoMigrate(&models.Task{}, &models.Tag{}, &models.Frequency{}, &models.TaskNote{}, &models.Snapshot{}, &models.SnapshotTask{}, &models.TaskResetEvent{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -60,7 +64,7 @@ func TestGetTaskNotFound(t *testing.T) {
 	r.GET("/tasks/:id", GetTask(db))
 
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/tasks/non-existent-id", nil)
+	req, _ := http.NewRequest("GET", "/tasks/00000000-0000-4000-8000-000000000000", nil)
 	r.ServeHTTP(w, req)
 
 	if w.Code != http.StatusNotFound {
@@ -72,6 +76,58 @@ func TestGetTaskNotFound(t *testing.T) {
 	}
 }
 
+func TestTaskHandlersRejectMalformedID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.GET("/tasks/:id", GetTask(db))
+	r.PUT("/tasks/:id", UpdateTask(db))
+	r.PATCH("/tasks/:id", PatchTask(db))
+	r.DELETE("/tasks/:id", DeleteTask(db))
+	r.POST("/tasks/:id/restore", RestoreTask(db))
+	r.POST("/tasks/:id/skip-next", SkipNextReset(db))
+	r.PUT("/tasks/:id/tags", SetTaskTags(db))
+	r.PUT("/tasks/:id/subtasks/reorder", ReorderSubtasks(db))
+	r.GET("/tasks/:id/notes", GetTaskNotes(db))
+	r.POST("/tasks/:id/notes", CreateTaskNote(db))
+	r.GET("/tasks/:id/history", GetTaskHistory(db))
+	r.POST("/tasks/:id/fan-out", FanOutTask(db))
+
+	routes := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"GET", "", ""},
+		{"PUT", "", `{"name": "Renamed"}`},
+		{"PATCH", "", `{"name": "Renamed"}`},
+		{"DELETE", "", ""},
+		{"POST", "/restore", ""},
+		{"POST", "/skip-next", ""},
+		{"PUT", "/tags", `{"tags": ["Work"]}`},
+		{"PUT", "/subtasks/reorder", `{"task_ids": []}`},
+		{"GET", "/notes", ""},
+		{"POST", "/notes", `{"body": "Note"}`},
+		{"GET", "/history", ""},
+		{"POST", "/fan-out", `{"tag_ids": []}`},
+	}
+
+	for _, route := range routes {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(route.method, "/tasks/not-a-uuid"+route.path, bytes.NewBufferString(route.body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s %s, got %d", http.StatusBadRequest, route.method, route.path, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "Invalid task ID") {
+			t.Errorf("Expected 'Invalid task ID' error message for %s %s, got %s", route.method, route.path, w.Body.String())
+		}
+	}
+}
+
 func TestCreateTask(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupTestHandlerDB(t)
@@ -104,6 +160,67 @@ func TestCreateTask(t *testing.T) {
 	}
 }
 
+func TestCreateTaskIdempotencyKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/api/tasks", CreateTask(db, broadcaster))
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/tasks", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var first, replay models.Task
+	w := post("create-1", `{"name": "Water plants"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &first)
+
+	w = post("create-1", `{"name": "Water plants"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d on replay, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("Expected replayed response to be marked")
+	}
+	json.Unmarshal(w.Body.Bytes(), &replay)
+	if replay.ID != first.ID {
+		t.Errorf("Expected replay to return task %s, got %s", first.ID, replay.ID)
+	}
+
+	// A failed request does not use up its key
+	if w := post("create-2", `{"name": "Feed cat", "priority": 99}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := post("create-2", `{"name": "Feed cat"}`); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Expected retried key to create a task, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	post("", `{"name": "Feed cat"}`)
+
+	var count int64
+	db.Model(&models.Task{}).Count(&count)
+	if count != 3 {
+		t.Errorf("Expected 3 tasks, got %d", count)
+	}
+	if len(broadcaster.events) != 3 {
+		t.Errorf("Expected 3 broadcasts, got %d", len(broadcaster.events))
+	}
+
+	if w := post(strings.Repeat("k", 256), `{"name": "Feed cat"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an overlong key, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
 func TestCreateTaskValidationError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupTestHandlerDB(t)
@@ -122,6 +239,24 @@ func TestCreateTaskValidationError(t *testing.T) {
 	}
 }
 
+func TestCreateTaskRecurUntilWithoutFrequency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/tasks", CreateTask(db))
+
+	requestBody := `{"name": "One-off", "recur_until": "2030-01-01T00:00:00Z"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
 func TestUpdateTaskNotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupTestHandlerDB(t)
@@ -131,7 +266,7 @@ func TestUpdateTaskNotFound(t *testing.T) {
 
 	requestBody := `{"name": "Updated Task"}`
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("PUT", "/tasks/non-existent-id", bytes.NewBufferString(requestBody))
+	req, _ := http.NewRequest("PUT", "/tasks/00000000-0000-4000-8000-000000000000", bytes.NewBufferString(requestBody))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
 
@@ -148,7 +283,7 @@ func TestDeleteTaskNotFound(t *testing.T) {
 	r.DELETE("/tasks/:id", DeleteTask(db))
 
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("DELETE", "/tasks/non-existent-id", nil)
+	req, _ := http.NewRequest("DELETE", "/tasks/00000000-0000-4000-8000-000000000000", nil)
 	r.ServeHTTP(w, req)
 
 	if w.Code != http.StatusNotFound {
@@ -246,6 +381,201 @@ func TestUpdateTaskRemovePriority(t *testing.T) {
 	}
 }
 
+func TestUpdateTaskEmptyName(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	task := models.Task{Name: "Test Task"}
+	db.Create(&task)
+
+	r := gin.New()
+	r.PUT("/api/tasks/:id", UpdateTask(db))
+	r.PATCH("/api/tasks/:id", PatchTask(db))
+
+	for _, method := range []string{"PUT", "PATCH"} {
+		for _, name := range []string{"", "   "} {
+			jsonData, _ := json.Marshal(map[string]any{"name": name})
+			req, _ := http.NewRequest(method, "/api/tasks/"+task.ID, bytes.NewBuffer(jsonData))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s with name %q: expected status %d, got %d", method, name, http.StatusBadRequest, w.Code)
+			}
+		}
+	}
+
+	// Leaving the name out keeps it unchanged
+	jsonData, _ := json.Marshal(map[string]any{"completed": true})
+	req, _ := http.NewRequest("PUT", "/api/tasks/"+task.ID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var updated models.Task
+	db.First(&updated, "id = ?", task.ID)
+	if updated.Name != "Test Task" || !updated.Completed {
+		t.Errorf("Expected name kept and task completed, got %+v", updated)
+	}
+}
+
+func TestPatchTask(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+	tag := models.Tag{Name: "Home", Color: "#ff0000"}
+	db.Create(&tag)
+
+	priority := 2
+	description := "Water the plants"
+	task := models.Task{Name: "Plants", Description: &description, Priority: &priority, FrequencyID: &frequency.ID, Tags: []models.Tag{tag}}
+	db.Create(&task)
+
+	r := gin.New()
+	r.PATCH("/api/tasks/:id", PatchTask(db))
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", "/api/tasks/"+task.ID, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Only the fields present are touched
+	if w := patch(`{"name": "Plants indoors"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var updated models.Task
+	db.Preload("Tags").First(&updated, "id = ?", task.ID)
+	if updated.Name != "Plants indoors" || updated.Priority == nil || updated.FrequencyID == nil || len(updated.Tags) != 1 {
+		t.Errorf("Expected only the name to change, got %+v", updated)
+	}
+
+	// Null clears optional fields
+	if w := patch(`{"priority": null, "frequency_id": null, "tag_ids": null}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	updated = models.Task{}
+	db.Preload("Tags").First(&updated, "id = ?", task.ID)
+	if updated.Priority != nil || updated.FrequencyID != nil || len(updated.Tags) != 0 {
+		t.Errorf("Expected priority, frequency and tags to be cleared, got %+v", updated)
+	}
+	if updated.Description == nil || *updated.Description != description {
+		t.Error("Expected description to be left unchanged")
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"Null name", `{"name": null}`},
+		{"Unknown field", `{"colour": "red"}`},
+		{"Invalid value", `{"priority": "high"}`},
+		{"Invalid priority", `{"priority": 99}`},
+		{"Not an object", `["name"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := patch(tt.body); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d. Body: %s", http.StatusBadRequest, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpdateTaskBroadcastsChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+	home := models.Tag{Name: "Home", Color: "#ff0000"}
+	work := models.Tag{Name: "Work", Color: "#00ff00"}
+	db.Create(&home)
+	db.Create(&work)
+
+	priority := 2
+	task := models.Task{Name: "Test Task", Priority: &priority, Tags: []models.Tag{home}}
+	db.Create(&task)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.PUT("/api/tasks/:id", UpdateTask(db, broadcaster))
+
+	jsonData, _ := json.Marshal(map[string]any{
+		"priority":     4,
+		"frequency_id": frequency.ID,
+		"tag_ids":      []string{work.ID},
+	})
+	req, _ := http.NewRequest("PUT", "/api/tasks/"+task.ID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if len(broadcaster.payloads) != 1 {
+		t.Fatalf("Expected 1 broadcast, got %d", len(broadcaster.payloads))
+	}
+
+	// Clients keep reading the task fields at the top level of the payload
+	payload, _ := json.Marshal(broadcaster.payloads[0])
+	var event struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Changes map[string]struct {
+			Old json.RawMessage `json:"old"`
+			New json.RawMessage `json:"new"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %v", err)
+	}
+	if event.ID != task.ID || event.Name != "Test Task" {
+		t.Errorf("Expected task fields in payload, got %s", payload)
+	}
+
+	expected := map[string][2]string{
+		"priority":     {"2", "4"},
+		"frequency_id": {"null", `"` + frequency.ID + `"`},
+		"tag_ids":      {`["` + home.ID + `"]`, `["` + work.ID + `"]`},
+	}
+	if len(event.Changes) != len(expected) {
+		t.Errorf("Expected %d changes, got %s", len(expected), payload)
+	}
+	for field, values := range expected {
+		change, ok := event.Changes[field]
+		if !ok {
+			t.Errorf("Expected change for %s", field)
+			continue
+		}
+		if string(change.Old) != values[0] || string(change.New) != values[1] {
+			t.Errorf("Expected %s to change from %s to %s, got %s to %s", field, values[0], values[1], change.Old, change.New)
+		}
+	}
+
+	// Updates leaving the tracked fields untouched report no changes
+	jsonData, _ = json.Marshal(map[string]any{"name": "Renamed", "priority": 4})
+	req, _ = http.NewRequest("PUT", "/api/tasks/"+task.ID, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	payload, _ = json.Marshal(broadcaster.payloads[1])
+	if strings.Contains(string(payload), `"changes"`) {
+		t.Errorf("Expected no changes in payload, got %s", payload)
+	}
+}
+
 func TestGetTasksFilterByTagNames(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupTestHandlerDB(t)
@@ -299,4 +629,1455 @@ func TestGetTasksFilterByTagNames(t *testing.T) {
 	if len(tasks) != 2 {
 		t.Errorf("Expected 2 tasks, got %d", len(tasks))
 	}
+
+	// Tag names match regardless of case and surrounding spaces
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks?tag=Warframe,%20GAMES", nil)
+	r.ServeHTTP(w, req)
+
+	tasks = nil
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 2 {
+		t.Errorf("Expected 2 tasks matching tag names case-insensitively, got %d", len(tasks))
+	}
+}
+
+func TestGetTasksFilterByCreatedRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	now := time.Now()
+	db.Create(&models.Task{Name: "Three days ago", CreatedAt: now.Add(-72 * time.Hour)})
+	db.Create(&models.Task{Name: "Two days ago", CreatedAt: now.Add(-48 * time.Hour)})
+	db.Create(&models.Task{Name: "Yesterday", CreatedAt: now.Add(-24 * time.Hour)})
+	db.Create(&models.Task{Name: "Today", CreatedAt: now})
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+
+	from := now.Add(-60 * time.Hour).UTC().Format(time.RFC3339)
+	to := now.Add(-12 * time.Hour).UTC().Format(time.RFC3339)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks?created_from="+from+"&created_to="+to, nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var tasks []models.Task
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].Name != "Two days ago" || tasks[1].Name != "Yesterday" {
+		t.Errorf("Expected 'Two days ago' and 'Yesterday', got '%s' and '%s'", tasks[0].Name, tasks[1].Name)
+	}
+
+	// Compose with the name filter
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks?created_from="+from+"&name=Yester", nil)
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 || tasks[0].Name != "Yesterday" {
+		t.Errorf("Expected only 'Yesterday', got %d tasks", len(tasks))
+	}
+}
+
+func TestGetTasksFilterByModifiedRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	now := time.Now()
+	for _, task := range []models.Task{
+		{Name: "Old untouched", CreatedAt: now.Add(-72 * time.Hour), UpdatedAt: now.Add(-72 * time.Hour)},
+		{Name: "Old edited", CreatedAt: now.Add(-72 * time.Hour), UpdatedAt: now.Add(-24 * time.Hour)},
+		{Name: "New", CreatedAt: now.Add(-24 * time.Hour), UpdatedAt: now.Add(-24 * time.Hour)},
+	} {
+		db.Create(&task)
+	}
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+
+	weekStart := now.Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"modified_after=" + weekStart, []string{"New", "Old edited"}},
+		{"modified_before=" + weekStart, []string{"Old untouched"}},
+		{"created_before=" + weekStart + "&modified_after=" + weekStart, []string{"Old edited"}},
+		{"created_after=" + weekStart + "&name=New", []string{"New"}},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/tasks?sort=name&"+tt.query, nil)
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d for %s, got %d. Body: %s", http.StatusOK, tt.query, w.Code, w.Body.String())
+		}
+
+		var tasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &tasks)
+		names := make([]string, len(tasks))
+		for i, task := range tasks {
+			names[i] = task.Name
+		}
+		if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("Expected %v for %s, got %v", tt.expected, tt.query, names)
+		}
+	}
+}
+
+func TestGetTasksFilterByCreatedRangeInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"Malformed from", "created_from=yesterday"},
+		{"Malformed to", "created_to=2024-01-01"},
+		{"Inverted range", "created_from=2024-02-01T00:00:00Z&created_to=2024-01-01T00:00:00Z"},
+		{"Malformed after", "created_after=last-week"},
+		{"Malformed modified before", "modified_before=2024-01-01"},
+		{"Inverted modified range", "modified_after=2024-02-01T00:00:00Z&modified_before=2024-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/tasks?"+tt.query, nil)
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestGetTasksFilterByStreak(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	db.Create(&models.Task{Name: "Neglected", CurrentStreak: 0})
+	db.Create(&models.Task{Name: "Building", CurrentStreak: 3})
+	db.Create(&models.Task{Name: "Strong", CurrentStreak: 7})
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks?min_streak=3", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var tasks []models.Task
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 2 || tasks[0].Name != "Building" || tasks[1].Name != "Strong" {
+		t.Errorf("Expected 'Building' and 'Strong', got %+v", tasks)
+	}
+
+	// Compose both thresholds with the name filter
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks?min_streak=1&max_streak=5&name=Build", nil)
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 || tasks[0].Name != "Building" {
+		t.Errorf("Expected only 'Building', got %d tasks", len(tasks))
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks?max_streak=-1", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for negative streak, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetTasksFilterByArchived(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	db.Create(&models.Task{Name: "Active"})
+	db.Create(&models.Task{Name: "Finished habit", Archived: true})
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks", nil)
+	r.ServeHTTP(w, req)
+
+	var tasks []models.Task
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 || tasks[0].Name != "Active" {
+		t.Errorf("Expected archived tasks to be hidden by default, got %d tasks", len(tasks))
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks?archived=true", nil)
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 || tasks[0].Name != "Finished habit" {
+		t.Errorf("Expected only the archived task, got %d tasks", len(tasks))
+	}
+}
+
+func TestTaskSource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/tasks", CreateTask(db))
+	r.GET("/tasks", GetTasks(db))
+
+	for _, tt := range []struct {
+		body   string
+		status int
+		source string
+	}{
+		{`{"name": "From the API"}`, http.StatusCreated, "api"},
+		{`{"name": "From the UI", "source": "web"}`, http.StatusCreated, "web"},
+		{`{"name": "From an agent", "source": "mcp"}`, http.StatusCreated, "mcp"},
+		{`{"name": "From nowhere", "source": "email"}`, http.StatusBadRequest, ""},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("Expected status %d for %s, got %d", tt.status, tt.body, w.Code)
+			continue
+		}
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		if task.Source != tt.source {
+			t.Errorf("Expected source %q for %s, got %q", tt.source, tt.body, task.Source)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks?source=mcp", nil)
+	r.ServeHTTP(w, req)
+
+	var tasks []models.Task
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 || tasks[0].Name != "From an agent" {
+		t.Errorf("Expected only the agent-created task, got %d tasks", len(tasks))
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks?source=email", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown source, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetTaskCounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
+	nextWeek := now.AddDate(0, 0, 7)
+
+	db.Create(&models.Task{Name: "Plain"})
+	db.Create(&models.Task{Name: "Overdue", DueDate: &yesterday})
+	db.Create(&models.Task{Name: "Due later today", DueDate: &endOfDay})
+	db.Create(&models.Task{Name: "Due next week", DueDate: &nextWeek})
+	db.Create(&models.Task{Name: "Done", Completed: true})
+	db.Create(&models.Task{Name: "Done and overdue", Completed: true, DueDate: &yesterday})
+	db.Create(&models.Task{Name: "Trashed", Deleted: true})
+
+	r := gin.New()
+	r.GET("/tasks/count", GetTaskCounts(db, time.Local))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks/count", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var counts TaskCounts
+	json.Unmarshal(w.Body.Bytes(), &counts)
+	expected := TaskCounts{Incomplete: 4, Completed: 2, Overdue: 1, DueToday: 1}
+	if counts != expected {
+		t.Errorf("Expected counts %+v, got %+v", expected, counts)
+	}
+}
+
+func TestCreateTaskWithConfiguredPriorityLevels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	if err := models.SetPriorityLevels(3); err != nil {
+		t.Fatalf("Failed to set priority levels: %v", err)
+	}
+	t.Cleanup(func() { models.SetPriorityLevels(models.DefaultPriorityLevels) })
+
+	r := gin.New()
+	r.POST("/tasks", CreateTask(db))
+
+	for body, expected := range map[string]int{
+		`{"name": "Top", "priority": 3}`:          http.StatusCreated,
+		`{"name": "Out of range", "priority": 4}`: http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != expected {
+			t.Errorf("Expected status %d for %s, got %d", expected, body, w.Code)
+		}
+	}
+}
+
+func TestGetNextTask(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.GET("/tasks/next", GetNextTask(db))
+
+	next := func() NextTaskResponse {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/tasks/next", nil)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		var response NextTaskResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response
+	}
+
+	if response := next(); !response.AllDone || response.Task != nil {
+		t.Errorf("Expected all done without tasks, got %+v", response)
+	}
+
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	nextWeek := time.Now().AddDate(0, 0, 7)
+	db.Create(&models.Task{Name: "No priority"})
+	db.Create(&models.Task{Name: "Low", Priority: intPtr(4)})
+	db.Create(&models.Task{Name: "High, due next week", Priority: intPtr(1), DueDate: &nextWeek})
+	db.Create(&models.Task{Name: "High, due tomorrow", Priority: intPtr(1), DueDate: &tomorrow})
+	db.Create(&models.Task{Name: "Completed", Priority: intPtr(1), Completed: true})
+	db.Create(&models.Task{Name: "Archived", Priority: intPtr(1), Archived: true})
+
+	response := next()
+	if response.AllDone || response.Task == nil {
+		t.Fatalf("Expected a task, got %+v", response)
+	}
+	if response.Task.Name != "High, due tomorrow" {
+		t.Errorf("Expected 'High, due tomorrow', got '%s'", response.Task.Name)
+	}
+}
+
+func TestGetBrokenScheduleTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	valid := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	broken := models.Frequency{Name: "Broken", Period: "every tuesday"}
+	db.Create(&valid)
+	db.Create(&broken)
+
+	db.Create(&models.Task{Name: "Healthy", FrequencyID: &valid.ID})
+	db.Create(&models.Task{Name: "Stuck", FrequencyID: &broken.ID})
+	db.Create(&models.Task{Name: "One-off"})
+
+	r := gin.New()
+	r.GET("/tasks/broken-schedule", GetBrokenScheduleTasks(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks/broken-schedule", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var tasks []models.Task
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 || tasks[0].Name != "Stuck" {
+		t.Errorf("Expected only 'Stuck', got %+v", tasks)
+	}
+}
+
+func TestGetPendingResetTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	daily := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&daily)
+
+	twoDaysAgo := time.Now().Add(-48 * time.Hour)
+	db.Create(&models.Task{Name: "Due", Completed: true, FrequencyID: &daily.ID, UpdatedAt: twoDaysAgo})
+	db.Create(&models.Task{Name: "Just done", Completed: true, FrequencyID: &daily.ID})
+	db.Create(&models.Task{Name: "Open", FrequencyID: &daily.ID, UpdatedAt: twoDaysAgo})
+
+	r := gin.New()
+	r.GET("/tasks/pending-reset", GetPendingResetTasks(db, time.UTC, "UTC"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks/pending-reset", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var tasks []models.Task
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 || tasks[0].Name != "Due" {
+		t.Errorf("Expected only 'Due', got %+v", tasks)
+	}
+}
+
+func TestGetTasksOverdueGrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+	t.Cleanup(func() { models.SetOverdueGrace(0) })
+
+	dueAt := time.Now().Add(-2 * time.Minute)
+	db.Create(&models.Task{Name: "Just missed", DueDate: &dueAt})
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+
+	tests := []struct {
+		grace    time.Duration
+		expected int
+	}{
+		{5 * time.Minute, 0},
+		{time.Minute, 1},
+	}
+
+	for _, tt := range tests {
+		models.SetOverdueGrace(tt.grace)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/tasks?overdue=true", nil)
+		r.ServeHTTP(w, req)
+
+		var tasks []models.Task
+		json.Unmarshal(w.Body.Bytes(), &tasks)
+		if len(tasks) != tt.expected {
+			t.Errorf("Expected %d overdue tasks with a %s grace, got %d", tt.expected, tt.grace, len(tasks))
+		}
+	}
+}
+
+func TestGetTasksPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	now := time.Now()
+	for i, name := range []string{"One", "Two", "Three", "Four", "Five"} {
+		db.Create(&models.Task{Name: name, CreatedAt: now.Add(time.Duration(i) * time.Minute)})
+	}
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks?limit=2&offset=2", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if total := w.Header().Get("X-Total-Count"); total != "5" {
+		t.Errorf("Expected X-Total-Count 5, got '%s'", total)
+	}
+
+	var tasks []models.Task
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 2 || tasks[0].Name != "Three" || tasks[1].Name != "Four" {
+		t.Errorf("Expected 'Three' and 'Four', got %+v", tasks)
+	}
+
+	for _, query := range []string{"limit=0", "limit=abc", "offset=-1"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/tasks?"+query, nil)
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
+}
+
+func TestGetTasksStableSortOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	// Tasks sharing a priority and creation time tie on every sort key but the ID
+	priority := 2
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var ids []string
+	for _, name := range []string{"Echo", "Alpha", "Delta", "Bravo", "Charlie"} {
+		task := models.Task{Name: name, Priority: &priority, CreatedAt: created}
+		db.Create(&task)
+		ids = append(ids, task.ID)
+	}
+	slices.Sort(ids)
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+
+	for _, sortBy := range []string{"priority", "completed", "position", "created_at"} {
+		for range 3 {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/tasks?sort="+sortBy, nil)
+			r.ServeHTTP(w, req)
+
+			var tasks []models.Task
+			json.Unmarshal(w.Body.Bytes(), &tasks)
+			var got []string
+			for _, task := range tasks {
+				got = append(got, task.ID)
+			}
+			if !slices.Equal(got, ids) {
+				t.Fatalf("Expected tasks sorted by %s to tie-break by ID, got %v", sortBy, got)
+			}
+		}
+	}
+}
+
+func TestGetTasksTotalCountWithTagFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	a := models.Tag{Name: "a", Color: "#ff0000"}
+	b := models.Tag{Name: "b", Color: "#00ff00"}
+	db.Create(&a)
+	db.Create(&b)
+	db.Create(&models.Task{Name: "Both", Tags: []models.Tag{a, b}})
+	db.Create(&models.Task{Name: "Only A", Tags: []models.Tag{a}})
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks?tag_ids="+a.ID+","+b.ID, nil)
+	r.ServeHTTP(w, req)
+
+	// A task matching several tags is only counted once
+	if total := w.Header().Get("X-Total-Count"); total != "2" {
+		t.Errorf("Expected X-Total-Count 2, got '%s'", total)
+	}
+}
+
+func TestGetTasksFieldSelection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	priority := 2
+	task := models.Task{Name: "Select me", Description: stringPtr("Long description"), Priority: &priority}
+	db.Create(&task)
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+	r.GET("/tasks/:id", GetTask(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tasks?fields=id,name,completed", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var tasks []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 {
+		t.Fatalf("Expected 1 task, got %d", len(tasks))
+	}
+	if len(tasks[0]) != 3 {
+		t.Errorf("Expected exactly 3 keys, got %v", tasks[0])
+	}
+	for _, key := range []string{"id", "name", "completed"} {
+		if _, ok := tasks[0][key]; !ok {
+			t.Errorf("Expected key '%s' to be present", key)
+		}
+	}
+
+	// Single task projection
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks/"+task.ID+"?fields=name,priority", nil)
+	r.ServeHTTP(w, req)
+
+	var single map[string]any
+	json.Unmarshal(w.Body.Bytes(), &single)
+	if len(single) != 2 || single["name"] != "Select me" || single["priority"] != float64(2) {
+		t.Errorf("Expected only name and priority, got %v", single)
+	}
+
+	// Unknown fields are rejected
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks?fields=id,bogus", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestTaskEstimateAndDueDateRoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/tasks", CreateTask(db))
+	r.PUT("/tasks/:id", UpdateTask(db))
+
+	requestBody := `{"name": "Plan", "estimated_minutes": 25, "due_date": "2025-03-14T17:00:00Z"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var task models.Task
+	json.Unmarshal(w.Body.Bytes(), &task)
+	if task.EstimatedMinutes == nil || *task.EstimatedMinutes != 25 {
+		t.Errorf("Expected estimated minutes 25, got %v", task.EstimatedMinutes)
+	}
+	if task.DueDate == nil || !task.DueDate.Equal(time.Date(2025, 3, 14, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected due date 2025-03-14T17:00:00Z, got %v", task.DueDate)
+	}
+
+	// Update the estimate and clear the due date
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/tasks/"+task.ID, bytes.NewBufferString(`{"estimated_minutes": 40, "due_date": ""}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var updated models.Task
+	db.First(&updated, "id = ?", task.ID)
+	if updated.EstimatedMinutes == nil || *updated.EstimatedMinutes != 40 {
+		t.Errorf("Expected estimated minutes 40, got %v", updated.EstimatedMinutes)
+	}
+	if updated.DueDate != nil {
+		t.Errorf("Expected due date to be cleared, got %v", updated.DueDate)
+	}
+
+	// Negative estimates are rejected
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/tasks/"+task.ID, bytes.NewBufferString(`{"estimated_minutes": -5}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestParseInlineTags(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedName string
+		expectedTags []string
+	}{
+		{"Buy milk #grocery #urgent", "Buy milk", []string{"grocery", "urgent"}},
+		{"#home Clean   kitchen", "Clean kitchen", []string{"home"}},
+		{"No tags here", "No tags here", nil},
+		{"Call #Work and #work", "Call and", []string{"Work"}},
+		{"Issue # 42", "Issue # 42", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, tags := parseInlineTags(tt.input)
+			if name != tt.expectedName {
+				t.Errorf("Expected name %q, got %q", tt.expectedName, name)
+			}
+			if strings.Join(tags, ",") != strings.Join(tt.expectedTags, ",") {
+				t.Errorf("Expected tags %v, got %v", tt.expectedTags, tags)
+			}
+		})
+	}
+}
+
+func TestCreateTaskParseInlineTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	existing := models.Tag{Name: "Grocery", Color: "#00ff00"}
+	db.Create(&existing)
+
+	r := gin.New()
+	r.POST("/tasks", CreateTask(db))
+
+	requestBody := `{"name": "Buy milk #grocery #urgent"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks?parse_tags=true", bytes.NewBufferString(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var task models.Task
+	json.Unmarshal(w.Body.Bytes(), &task)
+	if task.Name != "Buy milk" {
+		t.Errorf("Expected cleaned name 'Buy milk', got '%s'", task.Name)
+	}
+	if len(task.Tags) != 2 {
+		t.Fatalf("Expected 2 tags, got %d", len(task.Tags))
+	}
+
+	names := map[string]string{}
+	for _, tag := range task.Tags {
+		names[tag.Name] = tag.ID
+	}
+	if names["Grocery"] != existing.ID {
+		t.Error("Expected existing 'Grocery' tag to be reused")
+	}
+	if names["urgent"] == "" {
+		t.Error("Expected missing 'urgent' tag to be created")
+	}
+
+	var tagCount int64
+	db.Model(&models.Tag{}).Count(&tagCount)
+	if tagCount != 2 {
+		t.Errorf("Expected 2 tags in database, got %d", tagCount)
+	}
+
+	// Without the flag the name is kept verbatim
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/tasks", bytes.NewBufferString(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &task)
+	if task.Name != "Buy milk #grocery #urgent" {
+		t.Errorf("Expected name to be unchanged without parse_tags, got '%s'", task.Name)
+	}
+}
+
+func TestFanOutTask(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+	source := models.Task{Name: "Deploy", Description: stringPtr("Roll out release"), FrequencyID: &frequency.ID}
+	db.Create(&source)
+
+	tagNames := []string{"staging", "canary", "production"}
+	var tagIDs []string
+	for _, name := range tagNames {
+		tag := models.Tag{Name: name, Color: "#123456"}
+		db.Create(&tag)
+		tagIDs = append(tagIDs, tag.ID)
+	}
+
+	r := gin.New()
+	r.POST("/tasks/:id/fan-out", FanOutTask(db))
+
+	body, _ := json.Marshal(FanOutTaskRequest{TagIDs: tagIDs})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks/"+source.ID+"/fan-out", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var created []models.Task
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if len(created) != 3 {
+		t.Fatalf("Expected 3 tasks, got %d", len(created))
+	}
+	for i, task := range created {
+		if task.ID == source.ID {
+			t.Error("Expected a new task, got the source task")
+		}
+		if task.Name != "Deploy" || task.Description == nil || *task.Description != "Roll out release" {
+			t.Errorf("Expected copied attributes, got %+v", task)
+		}
+		if task.FrequencyID == nil || *task.FrequencyID != frequency.ID {
+			t.Error("Expected the frequency to be copied")
+		}
+		if len(task.Tags) != 1 || task.Tags[0].Name != tagNames[i] {
+			t.Errorf("Expected single tag '%s', got %+v", tagNames[i], task.Tags)
+		}
+	}
+
+	// Unknown tags are rejected without creating anything
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/tasks/"+source.ID+"/fan-out", bytes.NewBufferString(`{"tag_ids": ["missing"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestSetTaskTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	work := models.Tag{Name: "work", Color: "#ff0000"}
+	home := models.Tag{Name: "home", Color: "#00ff00"}
+	db.Create(&work)
+	db.Create(&home)
+
+	task := models.Task{Name: "Plan sprint", Tags: []models.Tag{home}}
+	db.Create(&task)
+
+	r := gin.New()
+	r.PUT("/tasks/:id/tags", SetTaskTags(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/tasks/"+task.ID+"/tags", bytes.NewBufferString(`{"tag_names": ["Work", "planning"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var updated models.Task
+	json.Unmarshal(w.Body.Bytes(), &updated)
+	names := make(map[string]bool)
+	for _, tag := range updated.Tags {
+		names[tag.Name] = true
+	}
+	if len(updated.Tags) != 2 || !names["work"] || !names["planning"] {
+		t.Errorf("Expected tags 'work' and 'planning', got %+v", updated.Tags)
+	}
+
+	var created models.Tag
+	if err := db.First(&created, "name = ?", "planning").Error; err != nil {
+		t.Errorf("Expected tag 'planning' to be created: %v", err)
+	}
+
+	var tagCount int64
+	db.Model(&models.Tag{}).Count(&tagCount)
+	if tagCount != 3 {
+		t.Errorf("Expected 3 tags in total, got %d", tagCount)
+	}
+}
+
+func TestBulkCreateTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+	morning := models.Tag{Name: "morning", Color: "#ffcc00"}
+	health := models.Tag{Name: "health", Color: "#00cc66"}
+	db.Create(&morning)
+	db.Create(&health)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/tasks/bulk-create", BulkCreateTasks(db, broadcaster))
+
+	body, _ := json.Marshal(BulkCreateTasksRequest{
+		Names:       []string{"Stretch", "Drink water", "Meditate"},
+		FrequencyID: &frequency.ID,
+		TagIDs:      []string{morning.ID, health.ID},
+		Priority:    intPtr(2),
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks/bulk-create", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var created []models.Task
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if len(created) != 3 {
+		t.Fatalf("Expected 3 tasks, got %d", len(created))
+	}
+	for _, task := range created {
+		if task.FrequencyID == nil || *task.FrequencyID != frequency.ID {
+			t.Errorf("Expected task '%s' to have the shared frequency", task.Name)
+		}
+		if task.Priority == nil || *task.Priority != 2 {
+			t.Errorf("Expected task '%s' to have priority 2", task.Name)
+		}
+		if len(task.Tags) != 2 {
+			t.Errorf("Expected task '%s' to have 2 tags, got %d", task.Name, len(task.Tags))
+		}
+	}
+
+	if len(broadcaster.events) != 1 {
+		t.Errorf("Expected a single broadcast, got %d", len(broadcaster.events))
+	}
+}
+
+func TestBatchCreateTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+	tag := models.Tag{Name: "setup", Color: "#ffcc00"}
+	db.Create(&tag)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/tasks/batch", BatchCreateTasks(db, broadcaster))
+
+	post := func(body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tasks/batch", bytes.NewBuffer(data))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post([]CreateTaskRequest{
+		{Name: "Create repository", Priority: intPtr(1), TagIDs: []string{tag.ID}},
+		{Name: "Write README", FrequencyID: &frequency.ID},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var created []models.Task
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if len(created) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(created))
+	}
+	if created[0].ID == "" || created[0].Name != "Create repository" || len(created[0].Tags) != 1 {
+		t.Errorf("Expected first task with ID and tag, got %+v", created[0])
+	}
+	if created[1].FrequencyID == nil || *created[1].FrequencyID != frequency.ID {
+		t.Errorf("Expected second task to have the frequency")
+	}
+	if len(broadcaster.events) != 1 {
+		t.Errorf("Expected a single broadcast, got %d", len(broadcaster.events))
+	}
+
+	// One invalid payload rolls back the whole batch
+	w = post([]CreateTaskRequest{
+		{Name: "Valid"},
+		{Name: "Invalid", FrequencyID: stringPtr("missing")},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "index 1") {
+		t.Errorf("Expected error to name the invalid index, got %s", w.Body.String())
+	}
+
+	var count int64
+	db.Model(&models.Task{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected no tasks from the failed batch, got %d tasks", count)
+	}
+
+	if w := post([]CreateTaskRequest{}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an empty batch, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestSubtasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+	r.GET("/tasks/:id", GetTask(db))
+	r.POST("/tasks", CreateTask(db))
+	r.PUT("/tasks/:id", UpdateTask(db))
+
+	parent := models.Task{Name: "Pack for trip"}
+	db.Create(&parent)
+
+	for _, name := range []string{"Passport", "Charger"} {
+		w := httptest.NewRecorder()
+		body := `{"name": "` + name + `", "parent_id": "` + parent.ID + `"}`
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+	}
+
+	// Unknown parents are rejected
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"name": "Orphan", "parent_id": "missing"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for unknown parent, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks/"+parent.ID, nil)
+	r.ServeHTTP(w, req)
+
+	var fetched models.Task
+	json.Unmarshal(w.Body.Bytes(), &fetched)
+	if len(fetched.Subtasks) != 2 {
+		t.Errorf("Expected 2 subtasks, got %d", len(fetched.Subtasks))
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks?top_level_only=true", nil)
+	r.ServeHTTP(w, req)
+
+	var tasks []models.Task
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	if len(tasks) != 1 || tasks[0].ID != parent.ID {
+		t.Errorf("Expected only the parent task, got %d tasks", len(tasks))
+	}
+
+	// Completing the parent with cascade completes its subtasks
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/tasks/"+parent.ID+"?cascade=true", bytes.NewBufferString(`{"completed": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var incomplete int64
+	db.Model(&models.Task{}).Where("parent_id = ? AND completed = ?", parent.ID, false).Count(&incomplete)
+	if incomplete != 0 {
+		t.Errorf("Expected all subtasks to be completed, %d are not", incomplete)
+	}
+}
+
+func TestDeleteTaskWithSubtasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.DELETE("/tasks/:id", DeleteTask(db))
+
+	tests := []struct {
+		name          string
+		query         string
+		expectDeleted bool
+	}{
+		{"Orphan", "", false},
+		{"Cascade", "?cascade=true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := models.Task{Name: "Parent"}
+			db.Create(&parent)
+			child := models.Task{Name: "Child", ParentID: &parent.ID}
+			db.Create(&child)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/tasks/"+parent.ID+tt.query, nil)
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+			}
+
+			db.First(&child, "id = ?", child.ID)
+			if child.Deleted != tt.expectDeleted {
+				t.Errorf("Expected child deleted=%v, got %v", tt.expectDeleted, child.Deleted)
+			}
+			if !tt.expectDeleted && child.ParentID != nil {
+				t.Error("Expected orphaned child to have no parent")
+			}
+		})
+	}
+}
+
+func TestBulkCompleteTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	first := models.Task{Name: "First"}
+	second := models.Task{Name: "Second"}
+	db.Create(&first)
+	db.Create(&second)
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.POST("/tasks/bulk-complete", BulkCompleteTasks(db, broadcaster))
+
+	body := `{"task_ids": ["` + first.ID + `", "missing", "` + second.ID + `"], "completed": true}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks/bulk-complete", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var results []BulkCompleteResult
+	json.Unmarshal(w.Body.Bytes(), &results)
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !results[0].Success || results[1].Success || !results[2].Success {
+		t.Errorf("Expected only the missing task to fail, got %+v", results)
+	}
+
+	var completed int64
+	db.Model(&models.Task{}).Where("completed = ?", true).Count(&completed)
+	if completed != 2 {
+		t.Errorf("Expected 2 completed tasks, got %d", completed)
+	}
+
+	if len(broadcaster.events) != 1 {
+		t.Errorf("Expected a single broadcast, got %d", len(broadcaster.events))
+	}
+}
+
+func TestReorderSubtasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	parent := models.Task{Name: "Pack for trip"}
+	other := models.Task{Name: "Clean house"}
+	db.Create(&parent)
+	db.Create(&other)
+
+	var subtasks []models.Task
+	for i, name := range []string{"Passport", "Charger", "Tickets"} {
+		subtask := models.Task{Name: name, ParentID: &parent.ID, Position: i}
+		db.Create(&subtask)
+		subtasks = append(subtasks, subtask)
+	}
+	foreign := models.Task{Name: "Vacuum", ParentID: &other.ID}
+	db.Create(&foreign)
+
+	r := gin.New()
+	r.PUT("/tasks/:id/subtasks/reorder", ReorderSubtasks(db))
+
+	// Tickets first, then Passport; the omitted Charger follows
+	body := `{"subtask_ids": ["` + subtasks[2].ID + `", "` + subtasks[0].ID + `"]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/tasks/"+parent.ID+"/subtasks/reorder", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var reordered models.Task
+	json.Unmarshal(w.Body.Bytes(), &reordered)
+	expected := []string{"Tickets", "Passport", "Charger"}
+	if len(reordered.Subtasks) != len(expected) {
+		t.Fatalf("Expected %d subtasks, got %d", len(expected), len(reordered.Subtasks))
+	}
+	for i, name := range expected {
+		if reordered.Subtasks[i].Name != name || reordered.Subtasks[i].Position != i {
+			t.Errorf("Expected %s at position %d, got %s at %d", name, i, reordered.Subtasks[i].Name, reordered.Subtasks[i].Position)
+		}
+	}
+
+	// Subtasks of another task are rejected
+	body = `{"subtask_ids": ["` + subtasks[0].ID + `", "` + foreign.ID + `"]}`
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/tasks/"+parent.ID+"/subtasks/reorder", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var passport models.Task
+	db.First(&passport, "id = ?", subtasks[0].ID)
+	if passport.Position != 1 {
+		t.Errorf("Expected rejected reorder to leave positions unchanged, got %d", passport.Position)
+	}
+}
+
+func TestListEndpointsReturnEmptyArrays(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+	r.GET("/tags", GetTags(db))
+	r.GET("/frequencies", GetFrequencies(db, time.UTC, "UTC"))
+	r.GET("/frequencies/timers", GetFrequencyTimers(db, time.UTC, "UTC"))
+
+	for _, path := range []string{"/tasks", "/tasks?fields=id,name", "/tags", "/frequencies", "/frequencies/timers"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusOK, path, w.Code)
+		}
+		if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+			t.Errorf("Expected empty array for %s, got %s", path, body)
+		}
+	}
+}
+
+func TestReorderTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	var ids []string
+	for _, name := range []string{"First", "Second", "Third"} {
+		task := models.Task{Name: name}
+		db.Create(&task)
+		ids = append(ids, task.ID)
+	}
+
+	broadcaster := &recordingBroadcaster{}
+	r := gin.New()
+	r.GET("/tasks", GetTasks(db))
+	r.PUT("/tasks/reorder", ReorderTasks(db, broadcaster))
+
+	body := `{"task_ids": ["` + ids[2] + `", "` + ids[0] + `", "` + ids[1] + `"]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/tasks/reorder", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if len(broadcaster.events) != 1 || broadcaster.events[0] != "task_list_refresh" {
+		t.Errorf("Expected a single task_list_refresh broadcast, got %v", broadcaster.events)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tasks?sort=position", nil)
+	r.ServeHTTP(w, req)
+
+	var tasks []models.Task
+	json.Unmarshal(w.Body.Bytes(), &tasks)
+	expected := []string{"Third", "First", "Second"}
+	for i, name := range expected {
+		if tasks[i].Name != name {
+			t.Errorf("Expected %s at position %d, got %s", name, i, tasks[i].Name)
+		}
+	}
+
+	// Unknown task IDs are rejected
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/tasks/reorder", bytes.NewBufferString(`{"task_ids": ["`+ids[0]+`", "missing"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for unknown task, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestReorderKeepsUpdatedAt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	completedAt := time.Now().Add(-time.Hour)
+	parent := models.Task{Name: "Parent", Completed: true, UpdatedAt: completedAt}
+	db.Create(&parent)
+	var ids []string
+	for _, name := range []string{"First", "Second"} {
+		subtask := models.Task{Name: name, ParentID: &parent.ID, Completed: true, UpdatedAt: completedAt}
+		db.Create(&subtask)
+		ids = append(ids, subtask.ID)
+	}
+
+	r := gin.New()
+	r.PUT("/tasks/reorder", ReorderTasks(db))
+	r.PUT("/tasks/:id/subtasks/reorder", ReorderSubtasks(db))
+
+	requests := []struct {
+		path string
+		body string
+	}{
+		{"/tasks/reorder", `{"task_ids": ["` + parent.ID + `"]}`},
+		{"/tasks/" + parent.ID + "/subtasks/reorder", `{"subtask_ids": ["` + ids[1] + `", "` + ids[0] + `"]}`},
+	}
+	for _, request := range requests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", request.path, bytes.NewBufferString(request.body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d for %s, got %d. Body: %s", http.StatusOK, request.path, w.Code, w.Body.String())
+		}
+	}
+
+	for _, id := range append(ids, parent.ID) {
+		var task models.Task
+		db.First(&task, "id = ?", id)
+		if !task.UpdatedAt.Equal(completedAt) {
+			t.Errorf("Expected reordering to leave updated_at of %s at %v, got %v", task.Name, completedAt, task.UpdatedAt)
+		}
+	}
+
+	var second models.Task
+	db.First(&second, "id = ?", ids[1])
+	if second.Position != 0 {
+		t.Errorf("Expected Second to move to position 0, got %d", second.Position)
+	}
+}
+
+func TestCreateTaskDefaultPriority(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	if err := models.SetDefaultPriority(4); err != nil {
+		t.Fatalf("Failed to set default priority: %v", err)
+	}
+	defer models.SetDefaultPriority(0)
+
+	r := gin.New()
+	r.POST("/tasks", CreateTask(db))
+
+	for body, expected := range map[string]int{
+		`{"name": "Defaulted"}`:               4,
+		`{"name": "Explicit", "priority": 1}`: 1,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+
+		var task models.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		if task.Priority == nil || *task.Priority != expected {
+			t.Errorf("Expected priority %d for %s, got %v", expected, body, task.Priority)
+		}
+	}
+}
+
+func TestTaskMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.POST("/tasks", CreateTask(db))
+	r.PUT("/tasks/:id", UpdateTask(db))
+	r.DELETE("/tasks/:id", DeleteTask(db))
+
+	created := testutil.ToFloat64(metrics.TasksCreated)
+	completed := testutil.ToFloat64(metrics.TasksCompleted)
+	deleted := testutil.ToFloat64(metrics.TasksDeleted)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"name": "Counted"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var task models.Task
+	json.Unmarshal(w.Body.Bytes(), &task)
+
+	// Completing an already completed task is not counted twice
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("PUT", "/tasks/"+task.ID, bytes.NewBufferString(`{"completed": true}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/tasks/"+task.ID, nil)
+	r.ServeHTTP(w, req)
+
+	if delta := testutil.ToFloat64(metrics.TasksCreated) - created; delta != 1 {
+		t.Errorf("Expected 1 created task, got %v", delta)
+	}
+	if delta := testutil.ToFloat64(metrics.TasksCompleted) - completed; delta != 1 {
+		t.Errorf("Expected 1 completed task, got %v", delta)
+	}
+	if delta := testutil.ToFloat64(metrics.TasksDeleted) - deleted; delta != 1 {
+		t.Errorf("Expected 1 deleted task, got %v", delta)
+	}
+}
+
+func TestSkipNextReset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	frequency := models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(&frequency)
+	recurring := models.Task{Name: "Recurring", Completed: true, FrequencyID: &frequency.ID}
+	oneOff := models.Task{Name: "One-off"}
+	db.Create(&recurring)
+	db.Create(&oneOff)
+
+	r := gin.New()
+	r.POST("/tasks/:id/skip-next", SkipNextReset(db))
+
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+	}{
+		{"Recurring", recurring.ID, http.StatusOK},
+		{"Without frequency", oneOff.ID, http.StatusBadRequest},
+		{"Unknown", "00000000-0000-4000-8000-000000000000", http.StatusNotFound},
+		{"Malformed", "missing", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/tasks/"+tt.id+"/skip-next", nil)
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expectedStatus, w.Code)
+		}
+	}
+
+	var stored models.Task
+	db.First(&stored, "id = ?", recurring.ID)
+	if !stored.SkipNextReset {
+		t.Error("Expected task to be marked to skip its next reset")
+	}
+	if !stored.UpdatedAt.Equal(recurring.UpdatedAt) {
+		t.Error("Expected updated_at to be left unchanged")
+	}
 }
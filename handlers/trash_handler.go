@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultTrashLimit is the number of trash items returned when no limit is given.
+	defaultTrashLimit = 50
+	// maxTrashLimit is the largest number of trash items returned in a single request.
+	maxTrashLimit = 200
+)
+
+// TrashItem represents a soft deleted task or tag that can be restored.
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// GetTrash returns a handler function for listing recently soft deleted tasks and tags,
+// newest first. Results can be narrowed with ?type=task|tag and bounded with ?limit=.
+func GetTrash(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		itemType := c.Query("type")
+		if itemType != "" && itemType != "task" && itemType != "tag" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Type must be 'task' or 'tag'"})
+			return
+		}
+
+		limit := defaultTrashLimit
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > maxTrashLimit {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be between 1 and 200"})
+				return
+			}
+			limit = parsed
+		}
+
+		items := []TrashItem{}
+
+		if itemType == "" || itemType == "task" {
+			var tasks []models.Task
+			// Tasks deleted before deletion timestamps were recorded fall back to their last update
+			if err := db.Where("deleted = ?", true).
+				Order("COALESCE(deleted_at, updated_at) DESC").
+				Limit(limit).
+				Find(&tasks).Error; err != nil {
+				log.Println("Error fetching deleted tasks:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted tasks"})
+				return
+			}
+
+			for _, task := range tasks {
+				deletedAt := task.UpdatedAt
+				if task.DeletedAt != nil {
+					deletedAt = *task.DeletedAt
+				}
+				items = append(items, TrashItem{Type: "task", ID: task.ID, Name: task.Name, DeletedAt: deletedAt})
+			}
+		}
+
+		if itemType == "" || itemType == "tag" {
+			var tags []models.Tag
+			if err := db.Unscoped().Where("deleted_at IS NOT NULL").
+				Order("deleted_at DESC").
+				Limit(limit).
+				Find(&tags).Error; err != nil {
+				log.Println("Error fetching deleted tags:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted tags"})
+				return
+			}
+
+			for _, tag := range tags {
+				items = append(items, TrashItem{Type: "tag", ID: tag.ID, Name: tag.Name, DeletedAt: tag.DeletedAt.Time})
+			}
+		}
+
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].DeletedAt.After(items[j].DeletedAt)
+		})
+		if len(items) > limit {
+			items = items[:limit]
+		}
+
+		c.JSON(http.StatusOK, items)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/models"
+)
+
+func TestGetTrash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	task := models.Task{Name: "Old chore"}
+	kept := models.Task{Name: "Still here"}
+	tag := models.Tag{Name: "Obsolete", Color: "#ff0000"}
+	db.Create(&task)
+	db.Create(&kept)
+	db.Create(&tag)
+	db.Model(&task).Association("Tags").Append(&tag)
+
+	r := gin.New()
+	r.DELETE("/tasks/:id", DeleteTask(db))
+	r.DELETE("/tags/:id", DeleteTag(db))
+	r.POST("/tasks/:id/restore", RestoreTask(db))
+	r.POST("/tags/:id/restore", RestoreTag(db))
+	r.GET("/trash", GetTrash(db))
+
+	for _, path := range []string{"/tasks/" + task.ID, "/tags/" + tag.ID} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", path, nil)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status %d deleting %s, got %d", http.StatusNoContent, path, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/trash", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var items []TrashItem
+	json.Unmarshal(w.Body.Bytes(), &items)
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 trash items, got %d", len(items))
+	}
+	if items[0].Type != "tag" || items[0].ID != tag.ID {
+		t.Errorf("Expected most recently deleted tag first, got %s %s", items[0].Type, items[0].Name)
+	}
+	if items[1].Type != "task" || items[1].ID != task.ID {
+		t.Errorf("Expected deleted task second, got %s %s", items[1].Type, items[1].Name)
+	}
+	for _, item := range items {
+		if item.DeletedAt.IsZero() {
+			t.Errorf("Expected deletion timestamp for %s", item.Name)
+		}
+	}
+
+	// Type filtering
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/trash?type=task", nil)
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &items)
+	if len(items) != 1 || items[0].Type != "task" {
+		t.Errorf("Expected only the deleted task, got %v", items)
+	}
+
+	// Restoring removes items from the trash
+	for _, path := range []string{"/tasks/" + task.ID + "/restore", "/tags/" + tag.ID + "/restore"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, nil)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d restoring %s, got %d", http.StatusOK, path, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/trash", nil)
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &items)
+	if len(items) != 0 {
+		t.Errorf("Expected empty trash after restore, got %d items", len(items))
+	}
+
+	// The restored task keeps its tag association
+	var restored models.Task
+	db.Preload("Tags").First(&restored, "id = ?", task.ID)
+	if restored.Deleted || len(restored.Tags) != 1 {
+		t.Errorf("Expected restored task with its tag, got deleted=%v tags=%d", restored.Deleted, len(restored.Tags))
+	}
+}
+
+func TestGetTrashInvalidType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestHandlerDB(t)
+
+	r := gin.New()
+	r.GET("/trash", GetTrash(db))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/trash?type=frequency", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -10,8 +10,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jhoffmann/dailies/config"
 	"github.com/jhoffmann/dailies/handlers"
+	"github.com/jhoffmann/dailies/metrics"
 	"github.com/jhoffmann/dailies/middleware"
+	"github.com/jhoffmann/dailies/models"
 	"github.com/jhoffmann/dailies/services"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // main initializes the application, sets up the database connection,
@@ -24,43 +27,111 @@ func main() {
 	}
 	log.Printf("Using timezone: %s", appConfig.Timezone)
 
-	db, err := config.SetupDatabase(appConfig.DBPath)
+	if err := models.SetTagPalette(appConfig.TagPalette); err != nil {
+		log.Fatalf("Failed to configure tag palette: %v", err)
+	}
+	if err := models.SetTagColorStrategy(appConfig.TagColorStrategy); err != nil {
+		log.Fatalf("Failed to configure tag color strategy: %v", err)
+	}
+	if err := models.SetTagColorsRestricted(appConfig.TagPaletteOnly); err != nil {
+		log.Fatalf("Failed to configure tag palette: %v", err)
+	}
+	if err := models.SetPriorityLevels(appConfig.PriorityLevels); err != nil {
+		log.Fatalf("Failed to configure priority levels: %v", err)
+	}
+	if err := models.SetDefaultPriority(appConfig.DefaultPriority); err != nil {
+		log.Fatalf("Failed to configure default priority: %v", err)
+	}
+	models.SetMinFrequencyInterval(appConfig.MinFrequencyInterval)
+	models.SetCronSeconds(appConfig.CronSeconds)
+	models.SetOverdueGrace(appConfig.OverdueGrace)
+
+	db, err := config.SetupDatabase(appConfig.DBDriver, appConfig.DBDSN)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
+	if err := config.ConfigurePool(db, appConfig.DBMaxOpenConns, appConfig.DBMaxIdleConns); err != nil {
+		log.Fatalf("Failed to configure database pool: %v", err)
+	}
+
+	if err := metrics.RegisterIncompleteTasksGauge(db); err != nil {
+		log.Fatalf("Failed to register metrics: %v", err)
+	}
 
 	// Initialize and start WebSocket manager
 	wsManager := services.NewWebSocketManager()
+	wsManager.SetIdleTimeout(appConfig.WSIdleTimeout)
+	wsManager.SetMaxClients(appConfig.WSMaxClients)
 	go wsManager.Run()
 
 	// Initialize and start the task scheduler
 	scheduler := services.NewTaskScheduler(db, appConfig.Location, appConfig.Timezone)
 	scheduler.SetWebSocketManager(wsManager)
+	scheduler.SetTrashRetention(appConfig.TrashRetention)
+	scheduler.SetPriorityEscalation(appConfig.PriorityEscalationAge, appConfig.EscalateAllTasks)
+	if appConfig.WebhookURL != "" {
+		webhook := services.NewWebhookNotifier(appConfig.WebhookURL)
+		defer webhook.Stop()
+		scheduler.SetWebhookNotifier(webhook)
+	}
 	scheduler.Start()
 	defer scheduler.Stop()
 
 	r := gin.Default()
 
-	r.Use(middleware.CORS())
+	// Serve "/path/" like "/path" rather than redirecting
+	r.RedirectTrailingSlash = false
+	r.NoRoute(middleware.TrailingSlash(r))
+
+	r.Use(middleware.CORS(appConfig.CORSOrigins...))
+	r.Use(middleware.Metrics())
 
-	api := r.Group("/api")
+	// Rate limit the API and WebSocket connections, leaving health checks unlimited
+	rateLimit := middleware.RateLimit(appConfig.RateLimit, appConfig.RateBurst)
+
+	// Require an API key for the API, metrics and WebSocket when keys are configured
+	api := r.Group("/api", rateLimit, middleware.APIKeyAuth(appConfig.APIKeys...))
 	{
 		tasks := api.Group("/tasks")
 		{
 			tasks.GET("", handlers.GetTasks(db))
+			tasks.GET("/count", handlers.GetTaskCounts(db, appConfig.Location))
+			tasks.GET("/next", handlers.GetNextTask(db))
+			tasks.GET("/broken-schedule", handlers.GetBrokenScheduleTasks(db))
+			tasks.GET("/pending-reset", handlers.GetPendingResetTasks(db, appConfig.Location, appConfig.Timezone))
 			tasks.GET("/:id", handlers.GetTask(db))
 			tasks.POST("", handlers.CreateTask(db, wsManager))
+			tasks.POST("/bulk-create", handlers.BulkCreateTasks(db, wsManager))
+			tasks.POST("/batch", handlers.BatchCreateTasks(db, wsManager))
+			tasks.POST("/bulk-complete", handlers.BulkCompleteTasks(db, wsManager))
+			tasks.PUT("/reorder", handlers.ReorderTasks(db, wsManager))
 			tasks.PUT("/:id", handlers.UpdateTask(db, wsManager))
+			tasks.PATCH("/:id", handlers.PatchTask(db, wsManager))
 			tasks.DELETE("/:id", handlers.DeleteTask(db, wsManager))
+			tasks.POST("/:id/restore", handlers.RestoreTask(db, wsManager))
+			tasks.POST("/:id/skip-next", handlers.SkipNextReset(db, wsManager))
+			tasks.PUT("/:id/tags", handlers.SetTaskTags(db, wsManager))
+			tasks.PUT("/:id/subtasks/reorder", handlers.ReorderSubtasks(db, wsManager))
+			tasks.GET("/:id/notes", handlers.GetTaskNotes(db))
+			tasks.GET("/:id/history", handlers.GetTaskHistory(db))
+			tasks.POST("/:id/notes", handlers.CreateTaskNote(db, wsManager))
+			tasks.POST("/:id/fan-out", handlers.FanOutTask(db, wsManager))
 		}
 
 		frequencies := api.Group("/frequencies")
 		{
-			frequencies.GET("", handlers.GetFrequencies(db))
+			frequencies.GET("", handlers.GetFrequencies(db, appConfig.Location, appConfig.Timezone))
 			frequencies.GET("/timers", handlers.GetFrequencyTimers(db, appConfig.Location, appConfig.Timezone))
+			frequencies.GET("/summary", handlers.GetFrequencySummary(db, appConfig.Location))
 			frequencies.GET("/:id", handlers.GetFrequency(db))
+			frequencies.GET("/by-name/:name", handlers.GetFrequencyByName(db))
+			frequencies.GET("/:id/schedule", handlers.GetFrequencySchedule(db, appConfig.Location, appConfig.Timezone))
 			frequencies.POST("", handlers.CreateFrequency(db, wsManager))
+			frequencies.POST("/move", handlers.MoveFrequencyTasks(db, wsManager))
+			frequencies.POST("/:id/clone-tasks", handlers.CloneFrequencyTasks(db, wsManager))
 			frequencies.PUT("/:id", handlers.UpdateFrequency(db, wsManager))
+			frequencies.POST("/:id/pause", handlers.PauseFrequency(db, wsManager))
+			frequencies.POST("/:id/resume", handlers.ResumeFrequency(db, wsManager))
 			frequencies.DELETE("/:id", handlers.DeleteFrequency(db, wsManager))
 		}
 
@@ -68,14 +139,58 @@ func main() {
 		{
 			tags.GET("", handlers.GetTags(db))
 			tags.GET("/:id", handlers.GetTag(db))
+			tags.GET("/:id/stats", handlers.GetTagStats(db))
+			tags.GET("/by-name/:name", handlers.GetTagByName(db))
+			tags.GET("/suggest", handlers.SuggestTags(db))
+			tags.GET("/palette", handlers.GetTagPalette())
+			tags.GET("/intersection", handlers.GetTagIntersection(db))
 			tags.POST("", handlers.CreateTag(db, wsManager))
 			tags.PUT("/:id", handlers.UpdateTag(db, wsManager))
 			tags.DELETE("/:id", handlers.DeleteTag(db, wsManager))
+			tags.POST("/:id/restore", handlers.RestoreTag(db, wsManager))
+			tags.POST("/:id/merge", handlers.MergeTag(db, wsManager))
+			tags.POST("/:id/assign", handlers.AssignTag(db, wsManager))
+			tags.POST("/:id/unassign", handlers.UnassignTag(db, wsManager))
+			tags.POST("/:id/complete-all", handlers.CompleteAllTagTasks(db, wsManager))
+			tags.POST("/:id/uncomplete-all", handlers.UncompleteAllTagTasks(db, wsManager))
+		}
+
+		api.DELETE("/notes/:id", handlers.DeleteNote(db, wsManager))
+		api.GET("/trash", handlers.GetTrash(db))
+		api.GET("/schedule", handlers.GetSchedule(db, appConfig.Location, appConfig.Timezone))
+		api.GET("/tasks.ics", handlers.ExportTasksICS(db, appConfig.Location, appConfig.Timezone))
+		api.GET("/tasks.csv", handlers.ExportTasksCSV(db))
+		api.GET("/export", handlers.ExportBackup(db))
+		api.POST("/import", handlers.ImportBackup(db, wsManager))
+
+		snapshots := api.Group("/snapshots")
+		{
+			snapshots.POST("", handlers.CreateSnapshot(db))
+			snapshots.POST("/:id/restore", handlers.RestoreSnapshot(db, wsManager))
+		}
+
+		// Admin routes expose the whole SQLite database, so they are only served behind an API key
+		if len(appConfig.APIKeys) > 0 && appConfig.DBDriver == config.DriverSQLite {
+			admin := api.Group("/admin")
+			{
+				admin.GET("/export.db", handlers.ExportDatabase(db))
+			}
+		}
+
+		stats := api.Group("/stats")
+		{
+			stats.GET("", handlers.GetStats(db))
+			stats.GET("/workload", handlers.GetWorkload(db, appConfig.Location, appConfig.Timezone))
 		}
 	}
 
-	r.GET("/health", handlers.GetHealth(db))
-	r.GET("/ws", wsManager.HandleWebSocket())
+	r.GET("/health", handlers.GetHealth(db, handlers.HealthComponents{
+		Config:    appConfig,
+		Scheduler: scheduler,
+		WebSocket: wsManager,
+	}))
+	r.GET("/metrics", middleware.APIKeyAuth(appConfig.APIKeys...), gin.WrapH(promhttp.Handler()))
+	r.GET("/ws", rateLimit, middleware.WebSocketAPIKeyAuth(appConfig.APIKeys...), wsManager.HandleWebSocket())
 
 	// Add timezone endpoint
 	api.GET("/timezone", handlers.GetTimezone(appConfig))
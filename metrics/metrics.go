@@ -0,0 +1,69 @@
+// Package metrics defines the Prometheus collectors exposed on the /metrics endpoint.
+// Collectors are registered with the default Prometheus registry.
+package metrics
+
+import (
+	"log"
+
+	"github.com/jhoffmann/dailies/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var (
+	// TasksCreated counts tasks created through the API.
+	TasksCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dailies_tasks_created_total",
+		Help: "Total number of tasks created.",
+	})
+
+	// TasksCompleted counts tasks marked as completed.
+	TasksCompleted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dailies_tasks_completed_total",
+		Help: "Total number of tasks marked as completed.",
+	})
+
+	// TasksDeleted counts tasks moved to the trash.
+	TasksDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dailies_tasks_deleted_total",
+		Help: "Total number of tasks deleted.",
+	})
+
+	// TasksReset counts completed tasks reset by the scheduler.
+	TasksReset = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dailies_tasks_reset_total",
+		Help: "Total number of completed tasks reset by the scheduler.",
+	})
+
+	// RequestDuration records the latency of HTTP requests by method, route and status.
+	RequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dailies_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// RegisterIncompleteTasksGauge registers a gauge reporting the number of incomplete
+// tasks, counted from the database each time metrics are collected. Deleted and
+// archived tasks are excluded.
+func RegisterIncompleteTasksGauge(db *gorm.DB) error {
+	return prometheus.Register(incompleteTasksGauge(db))
+}
+
+// incompleteTasksGauge creates the gauge registered by RegisterIncompleteTasksGauge.
+func incompleteTasksGauge(db *gorm.DB) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "dailies_tasks_incomplete",
+		Help: "Current number of incomplete tasks.",
+	}, func() float64 {
+		var count int64
+		if err := db.Model(&models.Task{}).
+			Where("completed = ? AND deleted = ? AND archived = ?", false, false, false).
+			Count(&count).Error; err != nil {
+			log.Println("Error counting incomplete tasks:", err)
+			return 0
+		}
+		return float64(count)
+	})
+}
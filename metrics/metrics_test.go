@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/jhoffmann/dailies/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestIncompleteTasksGauge(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Task{}, &models.Tag{}, &models.Frequency{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	gauge := incompleteTasksGauge(db)
+	if value := testutil.ToFloat64(gauge); value != 0 {
+		t.Errorf("Expected 0 incomplete tasks, got %v", value)
+	}
+
+	db.Create(&models.Task{Name: "Open"})
+	db.Create(&models.Task{Name: "Also open"})
+	db.Create(&models.Task{Name: "Done", Completed: true})
+	db.Create(&models.Task{Name: "Deleted", Deleted: true})
+	db.Create(&models.Task{Name: "Archived", Archived: true})
+
+	// The count is taken from the database at collection time
+	if value := testutil.ToFloat64(gauge); value != 2 {
+		t.Errorf("Expected 2 incomplete tasks, got %v", value)
+	}
+}
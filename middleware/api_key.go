@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyAuth returns a middleware function that requires an "Authorization: Bearer <key>"
+// header matching one of the given keys, responding 401 otherwise.
+// When no keys are given the middleware allows every request.
+func APIKeyAuth(keys ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		checkAPIKey(c, key, keys)
+	}
+}
+
+// WebSocketAPIKeyAuth returns a middleware function like APIKeyAuth that also accepts
+// the key as an "api_key" query parameter, since browsers cannot set headers on
+// WebSocket requests. When no keys are given the middleware allows every request.
+func WebSocketAPIKeyAuth(keys ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || key == "" {
+			key = c.Query("api_key")
+		}
+		checkAPIKey(c, key, keys)
+	}
+}
+
+// checkAPIKey continues the request when key matches one of keys, or when there are
+// no keys, and aborts it with 401 otherwise.
+func checkAPIKey(c *gin.Context, key string, keys []string) {
+	if len(keys) == 0 {
+		c.Next()
+		return
+	}
+
+	if key == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+		return
+	}
+
+	for _, valid := range keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(valid)) == 1 {
+			c.Next()
+			return
+		}
+	}
+
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+}
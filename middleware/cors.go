@@ -2,17 +2,41 @@
 package middleware
 
 import (
+	"net/http"
+	"slices"
+
 	"github.com/gin-gonic/gin"
 )
 
 // CORS returns a middleware function that sets Cross-Origin Resource Sharing headers
-// to allow requests from any origin with common HTTP methods and headers.
-func CORS() gin.HandlerFunc {
+// with common HTTP methods and headers. Requests from any origin are allowed unless
+// allowed origins are given, in which case only those origins are echoed back and
+// preflight requests from other origins are rejected with 403. An origin of "*"
+// allows any origin.
+func CORS(allowedOrigins ...string) gin.HandlerFunc {
+	allowAll := len(allowedOrigins) == 0 || slices.Contains(allowedOrigins, "*")
+
 	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
+		if allowAll {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			// Responses differ by origin, so caches must not share them across origins
+			c.Header("Vary", "Origin")
+			origin := c.GetHeader("Origin")
+			if !slices.Contains(allowedOrigins, origin) {
+				if c.Request.Method == "OPTIONS" && origin != "" {
+					c.AbortWithStatus(http.StatusForbidden)
+					return
+				}
+				c.Next()
+				return
+			}
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Idempotency-Key")
+		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
+		c.Header("Access-Control-Expose-Headers", "X-Total-Count")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -30,7 +30,7 @@ func TestCORS(t *testing.T) {
 	headers := map[string]string{
 		"Access-Control-Allow-Origin":      "*",
 		"Access-Control-Allow-Credentials": "true",
-		"Access-Control-Allow-Methods":     "POST, OPTIONS, GET, PUT, DELETE",
+		"Access-Control-Allow-Methods":     "POST, OPTIONS, GET, PUT, PATCH, DELETE",
 	}
 
 	for header, expectedValue := range headers {
@@ -42,7 +42,7 @@ func TestCORS(t *testing.T) {
 
 	// Check Access-Control-Allow-Headers contains expected values
 	allowHeaders := w.Header().Get("Access-Control-Allow-Headers")
-	expectedHeaders := []string{"Content-Type", "Authorization", "X-Requested-With"}
+	expectedHeaders := []string{"Content-Type", "Authorization", "X-Requested-With", "Idempotency-Key"}
 	for _, expected := range expectedHeaders {
 		if !contains(allowHeaders, expected) {
 			t.Errorf("Expected Access-Control-Allow-Headers to contain %s, got %s", expected, allowHeaders)
@@ -75,6 +75,70 @@ func TestCORSOptionsRequest(t *testing.T) {
 	}
 }
 
+func TestCORSAllowedOrigins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(CORS("https://dailies.example.com", "http://localhost:5173"))
+	r.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "test"})
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		origin         string
+		expectedStatus int
+		expectedOrigin string
+	}{
+		{"Allowed origin", "GET", "http://localhost:5173", http.StatusOK, "http://localhost:5173"},
+		{"Allowed preflight", "OPTIONS", "https://dailies.example.com", http.StatusNoContent, "https://dailies.example.com"},
+		{"Disallowed origin", "GET", "https://evil.example.com", http.StatusOK, ""},
+		{"Disallowed preflight", "OPTIONS", "https://evil.example.com", http.StatusForbidden, ""},
+		{"Same origin request", "GET", "", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, "/test", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != tt.expectedOrigin {
+				t.Errorf("Expected Access-Control-Allow-Origin %q, got %q", tt.expectedOrigin, origin)
+			}
+			if w.Header().Get("Vary") != "Origin" {
+				t.Error("Expected Vary: Origin header")
+			}
+		})
+	}
+}
+
+func TestCORSWildcardOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(CORS("*"))
+	r.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "test"})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("Origin", "https://anywhere.example.com")
+	r.ServeHTTP(w, req)
+
+	if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "*" {
+		t.Errorf("Expected Access-Control-Allow-Origin to be *, got %s", origin)
+	}
+}
+
 func TestCORSMiddlewareOrder(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhoffmann/dailies/metrics"
+)
+
+// Metrics returns a middleware function that records the latency of each request in the
+// request duration histogram, labelled by method, route template and response status.
+// Requests matching no route are grouped under an empty route label, and requests
+// re-routed by TrailingSlash are only recorded once, under the route they reached.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		if c.GetBool(reroutedKey) {
+			return
+		}
+
+		metrics.RequestDuration.
+			WithLabelValues(c.Request.Method, c.FullPath(), strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Metrics())
+	r.GET("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusTeapot)
+	})
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	for _, id := range []string{"1", "2"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/items/"+id, nil)
+		r.ServeHTTP(w, req)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	r.ServeHTTP(w, req)
+
+	// Both requests are recorded under the route template rather than the request path
+	expected := `dailies_http_request_duration_seconds_count{method="GET",route="/items/:id",status="418"} 2`
+	if !strings.Contains(w.Body.String(), expected) {
+		t.Errorf("Expected metrics output to contain %q", expected)
+	}
+}
+
+func TestMetricsTrailingSlash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.RedirectTrailingSlash = false
+	r.NoRoute(TrailingSlash(r))
+	r.Use(Metrics())
+	r.GET("/slashed", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/slashed/", nil)
+	r.ServeHTTP(w, req)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	r.ServeHTTP(w, req)
+
+	// The re-routed request is recorded once under its route, not again as unmatched
+	body := w.Body.String()
+	expected := `dailies_http_request_duration_seconds_count{method="GET",route="/slashed",status="200"} 1`
+	if !strings.Contains(body, expected) {
+		t.Errorf("Expected metrics output to contain %q", expected)
+	}
+	unmatched := `dailies_http_request_duration_seconds_count{method="GET",route="",status="200"}`
+	if strings.Contains(body, unmatched) {
+		t.Errorf("Expected metrics output not to contain %q", unmatched)
+	}
+}
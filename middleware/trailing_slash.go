@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reroutedKey marks a context that TrailingSlash handed back to the engine, so that
+// middleware wrapping the outer pass can tell the request was already handled.
+const reroutedKey = "middleware.rerouted"
+
+// TrailingSlash returns a handler that re-routes requests with trailing slashes to the
+// same path without them, so that "/api/tasks/" is served exactly like "/api/tasks"
+// instead of being redirected. It is meant to be registered with engine.NoRoute after
+// disabling engine.RedirectTrailingSlash. Paths that still do not match fall through
+// to the default 404 response. Global middleware runs again for the re-routed request,
+// so the outer pass is marked with reroutedKey for middleware that must not repeat.
+func TrailingSlash(engine *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if len(path) <= 1 || !strings.HasSuffix(path, "/") {
+			return
+		}
+
+		c.Request.URL.Path = strings.TrimRight(path, "/")
+		if c.Request.URL.Path == "" {
+			c.Request.URL.Path = "/"
+		}
+		engine.HandleContext(c)
+
+		// HandleContext resets the keys, so mark the request only once it has been served
+		c.Set(reroutedKey, true)
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupTrailingSlashRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.RedirectTrailingSlash = false
+	r.NoRoute(TrailingSlash(r))
+	r.GET("/tasks", func(c *gin.Context) {
+		c.String(http.StatusOK, "list")
+	})
+	r.POST("/tasks", func(c *gin.Context) {
+		c.String(http.StatusCreated, "create")
+	})
+	r.GET("/tasks/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "task "+c.Param("id"))
+	})
+	return r
+}
+
+func TestTrailingSlashCollection(t *testing.T) {
+	r := setupTrailingSlashRouter()
+
+	tests := []struct {
+		method   string
+		path     string
+		status   int
+		expected string
+	}{
+		{"GET", "/tasks", http.StatusOK, "list"},
+		{"GET", "/tasks/", http.StatusOK, "list"},
+		{"GET", "/tasks//", http.StatusOK, "list"},
+		{"POST", "/tasks/", http.StatusCreated, "create"},
+		{"GET", "/tasks/abc", http.StatusOK, "task abc"},
+		{"GET", "/tasks/abc/", http.StatusOK, "task abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if w.Body.String() != tt.expected {
+				t.Errorf("Expected body %q, got %q", tt.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTrailingSlashUnknownRoute(t *testing.T) {
+	r := setupTrailingSlashRouter()
+
+	for _, path := range []string{"/unknown", "/unknown/"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusNotFound, path, w.Code)
+		}
+	}
+}
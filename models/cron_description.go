@@ -0,0 +1,165 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// weekdayNames maps cron day-of-week numbers to their English names.
+var weekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// monthNames maps cron month numbers to their English names.
+var monthNames = []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+
+// descriptorDescriptions holds the English description of each supported cron descriptor.
+var descriptorDescriptions = map[string]string{
+	"@yearly":   "At 12:00 AM, on January 1",
+	"@annually": "At 12:00 AM, on January 1",
+	"@monthly":  "At 12:00 AM, on day 1 of the month",
+	"@weekly":   "At 12:00 AM, every Sunday",
+	"@daily":    "At 12:00 AM, every day",
+	"@midnight": "At 12:00 AM, every day",
+	"@hourly":   "Every hour",
+}
+
+// DescribeCron translates a cron expression into English, such as "At 6:00 PM, every day"
+// for "0 18 * * *". Expressions that are invalid or use a pattern it does not recognize
+// are returned unchanged.
+func DescribeCron(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if _, err := cronParser.Parse(expr); err != nil {
+		return expr
+	}
+
+	if description, ok := descriptorDescriptions[expr]; ok {
+		return description
+	}
+	if interval, ok := strings.CutPrefix(expr, "@every "); ok {
+		return "Every " + strings.TrimSpace(interval)
+	}
+
+	fields := strings.Fields(expr)
+	// A seconds field of 0 fires at the start of the minute, as 5-field expressions do
+	if len(fields) == 6 && fields[0] == "0" {
+		return DescribeCron(strings.Join(fields[1:], " "))
+	}
+	if len(fields) != 5 {
+		return expr
+	}
+	minute, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	// Sub-daily schedules only describe the minute and hour fields
+	if dom == "*" && month == "*" && dow == "*" {
+		switch {
+		case minute == "*" && hour == "*":
+			return "Every minute"
+		case strings.HasPrefix(minute, "*/") && hour == "*":
+			if step, err := strconv.Atoi(minute[2:]); err == nil {
+				return fmt.Sprintf("Every %d minutes", step)
+			}
+			return expr
+		case minute == "0" && hour == "*":
+			return "Every hour"
+		case hour == "*":
+			if m, err := strconv.Atoi(minute); err == nil {
+				return fmt.Sprintf("At minute %d of every hour", m)
+			}
+			return expr
+		}
+	}
+
+	clock, ok := describeClock(minute, hour)
+	if !ok {
+		return expr
+	}
+
+	switch {
+	case dom == "*" && month == "*" && dow == "*":
+		return clock + ", every day"
+	case dom == "*" && month == "*":
+		if days, ok := describeWeekdays(dow); ok {
+			return clock + ", every " + days
+		}
+	case month == "*" && dow == "*":
+		if day, err := strconv.Atoi(dom); err == nil {
+			return fmt.Sprintf("%s, on day %d of the month", clock, day)
+		}
+	case dow == "*":
+		day, dayErr := strconv.Atoi(dom)
+		m, monthErr := strconv.Atoi(month)
+		if dayErr == nil && monthErr == nil && m >= 1 && m <= 12 {
+			return fmt.Sprintf("%s, on %s %d", clock, monthNames[m], day)
+		}
+	}
+	return expr
+}
+
+// describeClock renders a single minute and hour as a 12-hour time like "At 6:00 PM".
+func describeClock(minute, hour string) (string, bool) {
+	m, err := strconv.Atoi(minute)
+	if err != nil {
+		return "", false
+	}
+	h, err := strconv.Atoi(hour)
+	if err != nil {
+		return "", false
+	}
+
+	period := "AM"
+	if h >= 12 {
+		period = "PM"
+	}
+	displayHour := h % 12
+	if displayHour == 0 {
+		displayHour = 12
+	}
+	return fmt.Sprintf("At %d:%02d %s", displayHour, m, period), true
+}
+
+// describeWeekdays renders a day-of-week field made of single days, lists or a range,
+// such as "Monday", "Monday, Wednesday and Friday" or "Monday through Friday".
+func describeWeekdays(dow string) (string, bool) {
+	if from, to, ok := strings.Cut(dow, "-"); ok {
+		first, ok := weekdayName(from)
+		if !ok {
+			return "", false
+		}
+		last, ok := weekdayName(to)
+		if !ok {
+			return "", false
+		}
+		return first + " through " + last, true
+	}
+
+	var names []string
+	for _, part := range strings.Split(dow, ",") {
+		name, ok := weekdayName(part)
+		if !ok {
+			return "", false
+		}
+		names = append(names, name)
+	}
+
+	if len(names) == 1 {
+		return names[0], true
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1], true
+}
+
+// weekdayName returns the English name of a numeric or abbreviated day of the week.
+func weekdayName(value string) (string, bool) {
+	if day, err := strconv.Atoi(value); err == nil {
+		if day < 0 || day >= len(weekdayNames) {
+			return "", false
+		}
+		return weekdayNames[day], true
+	}
+
+	for _, name := range weekdayNames {
+		if strings.EqualFold(value, name[:3]) {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestDescribeCron(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"0 18 * * *", "At 6:00 PM, every day"},
+		{"30 0 * * *", "At 12:30 AM, every day"},
+		{"0 9 * * 1", "At 9:00 AM, every Monday"},
+		{"0 9 * * 1-5", "At 9:00 AM, every Monday through Friday"},
+		{"0 9 * * 1,3,5", "At 9:00 AM, every Monday, Wednesday and Friday"},
+		{"0 12 * * SAT", "At 12:00 PM, every Saturday"},
+		{"0 8 15 * *", "At 8:00 AM, on day 15 of the month"},
+		{"0 8 25 12 *", "At 8:00 AM, on December 25"},
+		{"* * * * *", "Every minute"},
+		{"*/15 * * * *", "Every 15 minutes"},
+		{"0 * * * *", "Every hour"},
+		{"45 * * * *", "At minute 45 of every hour"},
+		{"@daily", "At 12:00 AM, every day"},
+		{"@weekly", "At 12:00 AM, every Sunday"},
+		{"@every 90m", "Every 90m"},
+		// Unrecognized or invalid expressions are returned unchanged
+		{"0 9-17 * * *", "0 9-17 * * *"},
+		{"0 9 1 * 1", "0 9 1 * 1"},
+		{"not a cron", "not a cron"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := DescribeCron(tt.expr); got != tt.expected {
+				t.Errorf("DescribeCron(%q) = %q, expected %q", tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFrequencyDescription(t *testing.T) {
+	db := setupTestDB(t)
+
+	frequency := Frequency{Name: "Evening", Period: "0 18 * * *"}
+	if err := db.Create(&frequency).Error; err != nil {
+		t.Fatalf("Failed to create frequency: %v", err)
+	}
+	if frequency.Description != "At 6:00 PM, every day" {
+		t.Errorf("Expected description after create, got %q", frequency.Description)
+	}
+
+	var fetched Frequency
+	db.First(&fetched, "id = ?", frequency.ID)
+	if fetched.Description != "At 6:00 PM, every day" {
+		t.Errorf("Expected description after find, got %q", fetched.Description)
+	}
+}
@@ -10,13 +10,50 @@ import (
 )
 
 // Frequency represents a recurring schedule for tasks (e.g., daily, weekly).
+// Tasks of a disabled (paused) frequency are not reset by the scheduler. When a reminder
+// lead is set, incomplete tasks are reminded that many minutes before the frequency fires.
+// TaskCount and NextFire are computed when listing frequencies and are not stored.
 type Frequency struct {
-	ID        string    `json:"id" gorm:"type:text;primaryKey"`
-	Name      string    `json:"name" gorm:"not null;unique"`
-	Period    string    `json:"period" gorm:"not null"`
-	Tasks     []Task    `json:"tasks,omitempty" gorm:"foreignKey:FrequencyID"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            string     `json:"id" gorm:"type:text;primaryKey"`
+	Name          string     `json:"name" gorm:"not null;unique"`
+	Period        string     `json:"period" gorm:"not null"`
+	ResetBoundary string     `json:"reset_boundary" gorm:"not null;default:start"`
+	ResetMode     string     `json:"reset_mode" gorm:"not null;default:reuse"`
+	Enabled       bool       `json:"enabled" gorm:"not null;default:true"`
+	ReminderLead  *int       `json:"reminder_lead_minutes,omitempty" gorm:"column:reminder_lead_minutes;check:reminder_lead_minutes > 0"`
+	Description   string     `json:"description" gorm:"-"`
+	Tasks         []Task     `json:"tasks,omitempty" gorm:"foreignKey:FrequencyID"`
+	TaskCount     int        `json:"task_count" gorm:"-"`
+	NextFire      *time.Time `json:"next_reset,omitempty" gorm:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Reset boundaries accepted by Frequency.ResetBoundary.
+const (
+	// ResetBoundaryStart resets a completed task when the next period starts.
+	ResetBoundaryStart = "start"
+	// ResetBoundaryEnd resets a completed task one full period after its completion.
+	ResetBoundaryEnd = "end"
+)
+
+// ValidResetBoundary reports whether boundary is a supported reset boundary.
+func ValidResetBoundary(boundary string) bool {
+	return boundary == ResetBoundaryStart || boundary == ResetBoundaryEnd
+}
+
+// Reset modes accepted by Frequency.ResetMode.
+const (
+	// ResetModeReuse marks a completed task incomplete again when it resets.
+	ResetModeReuse = "reuse"
+	// ResetModeClone archives a completed task when it resets, keeping it as history,
+	// and creates an incomplete copy for the next period.
+	ResetModeClone = "clone"
+)
+
+// ValidResetMode reports whether mode is a supported reset mode.
+func ValidResetMode(mode string) bool {
+	return mode == ResetModeReuse || mode == ResetModeClone
 }
 
 // BeforeCreate is a GORM hook that generates a UUID for the frequency before creation.
@@ -27,11 +64,159 @@ func (f *Frequency) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// AfterFind is a GORM hook that fills in the human-readable description of the period.
+func (f *Frequency) AfterFind(tx *gorm.DB) error {
+	f.Description = DescribeCron(f.Period)
+	return nil
+}
+
+// AfterSave is a GORM hook that refreshes the human-readable description of the period.
+func (f *Frequency) AfterSave(tx *gorm.DB) error {
+	f.Description = DescribeCron(f.Period)
+	return nil
+}
+
+// minuteCronParser parses 5-field cron expressions (minute hour day month day-of-week)
+// as well as descriptors like @daily.
+var minuteCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// secondCronParser additionally accepts an optional leading seconds field. Expressions
+// without one fire at second 0, so 5-field expressions keep their meaning.
+var secondCronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// cronParser holds the parser for the cron precision selected at startup.
+var cronParser = minuteCronParser
+
+// cronSeconds reports whether cronParser accepts a seconds field.
+var cronSeconds bool
+
+// SetCronSeconds sets whether cron expressions may include a leading seconds field.
+func SetCronSeconds(enabled bool) {
+	cronSeconds = enabled
+	if enabled {
+		cronParser = secondCronParser
+	} else {
+		cronParser = minuteCronParser
+	}
+}
+
+// CronSeconds reports whether cron expressions may include a leading seconds field.
+func CronSeconds() bool {
+	return cronSeconds
+}
+
+// ParseCron parses a cron expression with the configured precision.
+func ParseCron(expr string) (cron.Schedule, error) {
+	return cronParser.Parse(expr)
+}
+
+// Schedule parses the frequency's cron expression so that it fires in the specified timezone.
+func (f *Frequency) Schedule(timezone string) (cron.Schedule, error) {
+	return cronParser.Parse("TZ=" + timezone + " " + f.Period)
+}
+
+// FireTimes returns every scheduled reset time at or after from and before to,
+// in chronological order.
+func (f *Frequency) FireTimes(timezone string, from, to time.Time) ([]time.Time, error) {
+	schedule, err := f.Schedule(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var times []time.Time
+	for next := schedule.Next(from.Add(-time.Second)); !next.IsZero() && next.Before(to); next = schedule.Next(next) {
+		if next.Before(from) {
+			continue
+		}
+		times = append(times, next)
+	}
+	return times, nil
+}
+
+// NextReset returns when a task completed at completedAt should reset. With the start
+// boundary this is the next scheduled time after completion; with the end boundary the
+// task stays completed for a full period, measured between the next two scheduled times.
+func (f *Frequency) NextReset(completedAt time.Time, timezone string) (time.Time, error) {
+	schedule, err := f.Schedule(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := schedule.Next(completedAt)
+	if f.ResetBoundary != ResetBoundaryEnd || next.IsZero() {
+		return next, nil
+	}
+
+	following := schedule.Next(next)
+	if following.IsZero() {
+		return next, nil
+	}
+	return completedAt.Add(following.Sub(next)), nil
+}
+
+// intervalSamples is the number of consecutive fires checked by ValidateInterval.
+const intervalSamples = 50
+
+// minFrequencyInterval holds the shortest allowed gap between fires, selected at startup.
+var minFrequencyInterval time.Duration
+
+// SetMinFrequencyInterval sets the shortest allowed gap between two consecutive fires
+// of a frequency. A zero duration disables the check.
+func SetMinFrequencyInterval(interval time.Duration) {
+	minFrequencyInterval = interval
+}
+
+// ValidateInterval returns an error when any two of the upcoming consecutive fires of
+// the frequency are closer together than the configured minimum interval.
+func (f *Frequency) ValidateInterval() error {
+	if minFrequencyInterval <= 0 {
+		return nil
+	}
+
+	schedule, err := cronParser.Parse(f.Period)
+	if err != nil {
+		return err
+	}
+
+	previous := schedule.Next(time.Now())
+	for i := 0; i < intervalSamples && !previous.IsZero(); i++ {
+		next := schedule.Next(previous)
+		if next.IsZero() {
+			break
+		}
+		if next.Sub(previous) < minFrequencyInterval {
+			return fmt.Errorf("fires every %s, more often than the minimum interval of %s", next.Sub(previous), minFrequencyInterval)
+		}
+		previous = next
+	}
+	return nil
+}
+
+// maxTimeUntilFire is the longest a frequency may take to next fire before it is
+// considered to never fire.
+const maxTimeUntilFire = 5 * 365 * 24 * time.Hour
+
+// ValidateFires returns an error when the frequency's cron expression is valid but never
+// matches, such as February 30th, or does not fire within the next five years.
+func (f *Frequency) ValidateFires() error {
+	schedule, err := cronParser.Parse(f.Period)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	next := schedule.Next(now)
+	if next.IsZero() || next.Sub(now) > maxTimeUntilFire {
+		return fmt.Errorf("never fires within the next five years")
+	}
+	return nil
+}
+
 // TimeUntilNextReset calculates how long until the next reset based on the cron schedule
-// using the specified timezone. Returns a human-readable duration string like "6h", "2d", "12m".
+// using the specified timezone. Returns a human-readable duration string like "6h", "2d", "12m",
+// or "45s" under a minute when cron seconds are enabled.
 func (f *Frequency) TimeUntilNextReset(location *time.Location, timezone string) (string, error) {
-	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	schedule, err := parser.Parse("TZ=" + timezone + " " + f.Period)
+	schedule, err := f.Schedule(timezone)
 	if err != nil {
 		return "", err
 	}
@@ -62,5 +247,8 @@ func formatDuration(d time.Duration) string {
 	if minutes > 0 {
 		return fmt.Sprintf("%dm", minutes)
 	}
+	if cronSeconds {
+		return fmt.Sprintf("%ds", max(1, int(d.Seconds())))
+	}
 	return "1m" // Show at least 1 minute if less than a minute remains
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -81,3 +82,156 @@ func TestFrequencyCreation(t *testing.T) {
 		t.Errorf("Expected period 'daily', got %s", retrievedFrequency.Period)
 	}
 }
+
+func TestFrequencySchedule(t *testing.T) {
+	frequency := &Frequency{Name: "Daily", Period: "0 9 * * *"}
+
+	schedule, err := frequency.Schedule("America/New_York")
+	if err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	next := schedule.Next(time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC))
+	expected := time.Date(2025, 3, 14, 13, 0, 0, 0, time.UTC) // 09:00 EDT
+	if !next.Equal(expected) {
+		t.Errorf("Expected next fire at %v, got %v", expected, next)
+	}
+
+	invalid := &Frequency{Name: "Invalid", Period: "invalid cron"}
+	if _, err := invalid.Schedule("UTC"); err == nil {
+		t.Error("Expected error for invalid cron expression")
+	}
+}
+
+func TestFrequencyFireTimes(t *testing.T) {
+	frequency := &Frequency{Name: "Daily", Period: "0 9 * * *"}
+
+	from := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 16, 9, 0, 0, 0, time.UTC)
+
+	times, err := frequency.FireTimes("UTC", from, to)
+	if err != nil {
+		t.Fatalf("FireTimes failed: %v", err)
+	}
+
+	// The window includes its start and excludes its end
+	expected := []time.Time{from, from.AddDate(0, 0, 1)}
+	if len(times) != len(expected) {
+		t.Fatalf("Expected %d fire times, got %d: %v", len(expected), len(times), times)
+	}
+	for i := range expected {
+		if !times[i].Equal(expected[i]) {
+			t.Errorf("Expected fire time %v, got %v", expected[i], times[i])
+		}
+	}
+}
+
+func TestFrequencyNextResetBoundaries(t *testing.T) {
+	// Weekly on Monday at midnight; completed on Wednesday 2025-03-12 at noon
+	completedAt := time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		boundary string
+		expected time.Time
+	}{
+		// Resets when the next week starts
+		{ResetBoundaryStart, time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)},
+		// Stays completed for a full week after completion
+		{ResetBoundaryEnd, time.Date(2025, 3, 19, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.boundary, func(t *testing.T) {
+			frequency := &Frequency{Name: "Weekly", Period: "0 0 * * 1", ResetBoundary: tt.boundary}
+
+			next, err := frequency.NextReset(completedAt, "UTC")
+			if err != nil {
+				t.Fatalf("NextReset failed: %v", err)
+			}
+			if !next.Equal(tt.expected) {
+				t.Errorf("Expected next reset %v, got %v", tt.expected, next)
+			}
+		})
+	}
+}
+
+func TestFrequencyValidateFires(t *testing.T) {
+	tests := []struct {
+		period string
+		valid  bool
+	}{
+		{"0 0 30 2 *", false},
+		{"0 0 31 4 *", false},
+		{"0 0 29 2 *", true},
+		{"@yearly", true},
+		{"0 0 * * *", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			err := (&Frequency{Period: tt.period}).ValidateFires()
+			if (err == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got error %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestCronSeconds(t *testing.T) {
+	if _, err := ParseCron("*/10 * * * * *"); err == nil {
+		t.Error("Expected a seconds field to be rejected by default")
+	}
+
+	SetCronSeconds(true)
+	t.Cleanup(func() { SetCronSeconds(false) })
+
+	schedule, err := ParseCron("*/10 * * * * *")
+	if err != nil {
+		t.Fatalf("Expected a seconds field to be accepted: %v", err)
+	}
+	start := time.Date(2025, 1, 1, 12, 0, 3, 0, time.UTC)
+	if next := schedule.Next(start); !next.Equal(start.Add(7 * time.Second)) {
+		t.Errorf("Expected next fire at 12:00:10, got %v", next)
+	}
+
+	// Expressions without seconds keep firing at the start of the minute
+	schedule, err = ParseCron("0 12 * * *")
+	if err != nil {
+		t.Fatalf("Expected a 5-field expression to be accepted: %v", err)
+	}
+	if next := schedule.Next(start); !next.Equal(time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected next fire at 12:00:00 the next day, got %v", next)
+	}
+
+	if got := DescribeCron("0 0 18 * * *"); got != "At 6:00 PM, every day" {
+		t.Errorf("Expected a zero seconds field to be described like 5 fields, got %q", got)
+	}
+	if got := formatDuration(30 * time.Second); got != "30s" {
+		t.Errorf("Expected durations under a minute in seconds, got %q", got)
+	}
+}
+
+func TestFrequencyValidateInterval(t *testing.T) {
+	SetMinFrequencyInterval(5 * time.Minute)
+	t.Cleanup(func() { SetMinFrequencyInterval(0) })
+
+	tests := []struct {
+		period string
+		valid  bool
+	}{
+		{"* * * * *", false},
+		// Fires every minute, but only during one hour of the day
+		{"* 9 * * *", false},
+		{"*/5 * * * *", true},
+		{"0 0 * * *", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			err := (&Frequency{Period: tt.period}).ValidateInterval()
+			if (err == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got error %v", tt.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Snapshot represents a named checkpoint of the completion state of all tasks.
+type Snapshot struct {
+	ID        string         `json:"id" gorm:"type:text;primaryKey"`
+	Name      string         `json:"name" gorm:"not null"`
+	Tasks     []SnapshotTask `json:"tasks,omitempty" gorm:"foreignKey:SnapshotID"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// SnapshotTask records whether a single task was completed when a snapshot was taken.
+type SnapshotTask struct {
+	SnapshotID string `json:"snapshot_id" gorm:"type:text;primaryKey"`
+	TaskID     string `json:"task_id" gorm:"type:text;primaryKey"`
+	Completed  bool   `json:"completed" gorm:"not null"`
+}
+
+// BeforeCreate is a GORM hook that generates a UUID for the snapshot before creation.
+func (s *Snapshot) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
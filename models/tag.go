@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,13 +10,19 @@ import (
 )
 
 // Tag represents a categorization label that can be assigned to tasks.
+// TaskCount is computed when listing tags and is not stored.
 type Tag struct {
-	ID        string    `json:"id" gorm:"type:text;primaryKey"`
-	Name      string    `json:"name" gorm:"not null;unique"`
-	Color     string    `json:"color" gorm:"not null"`
-	Tasks     []Task    `json:"tasks,omitempty" gorm:"many2many:task_tags;"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                   string    `json:"id" gorm:"type:text;primaryKey"`
+	Name                 string    `json:"name" gorm:"not null;unique"`
+	Color                string    `json:"color" gorm:"not null"`
+	NotificationsEnabled bool      `json:"notifications_enabled" gorm:"not null;default:true"`
+	AutoEscalate         bool      `json:"auto_escalate" gorm:"not null;default:false"`
+	Tasks                []Task    `json:"tasks,omitempty" gorm:"many2many:task_tags;"`
+	TaskCount            int       `json:"task_count" gorm:"-"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+	// DeletedAt enables GORM soft deletes so deleted tags can be restored from the trash
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // BeforeCreate is a GORM hook that generates a UUID for the tag before creation.
@@ -24,3 +32,102 @@ func (t *Tag) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// Palette names accepted by SetTagPalette.
+const (
+	TagPaletteDefault    = "default"
+	TagPaletteColorblind = "colorblind"
+)
+
+// tagPalettes maps palette names to the colors new tags draw from.
+// A nil palette means colors are generated randomly.
+var tagPalettes = map[string][]string{
+	TagPaletteDefault: nil,
+	// Okabe-Ito palette, distinguishable under the common forms of color blindness
+	TagPaletteColorblind: {"#e69f00", "#56b4e9", "#009e73", "#f0e442", "#0072b2", "#d55e00", "#cc79a7", "#999999"},
+}
+
+// tagColors holds the active palette selected at startup.
+var tagColors []string
+
+// tagPaletteName holds the name of the active palette.
+var tagPaletteName = TagPaletteDefault
+
+// tagColorsRestricted reports whether tags may only use colors from the active palette.
+var tagColorsRestricted bool
+
+// SetTagPalette selects the palette that new tag colors are drawn from.
+func SetTagPalette(name string) error {
+	palette, ok := tagPalettes[name]
+	if !ok {
+		return fmt.Errorf("unknown tag palette '%s'", name)
+	}
+	tagColors = palette
+	tagPaletteName = name
+	return nil
+}
+
+// Color strategies accepted by SetTagColorStrategy, used when the palette has no fixed colors.
+const (
+	// TagColorStrategyRandom generates fully random colors.
+	TagColorStrategyRandom = "random"
+	// TagColorStrategyReadable generates colors within a moderate saturation and
+	// lightness band, avoiding near-white, near-black and washed out colors.
+	TagColorStrategyReadable = "readable"
+)
+
+// tagColorStrategy holds the strategy selected at startup.
+var tagColorStrategy = TagColorStrategyRandom
+
+// SetTagColorStrategy selects how colors are generated for palettes without fixed colors.
+func SetTagColorStrategy(name string) error {
+	if name != TagColorStrategyRandom && name != TagColorStrategyReadable {
+		return fmt.Errorf("unknown tag color strategy '%s'", name)
+	}
+	tagColorStrategy = name
+	return nil
+}
+
+// TagColorStrategy returns the active tag color strategy.
+func TagColorStrategy() string {
+	return tagColorStrategy
+}
+
+// TagColors returns the active tag palette, or nil when colors are generated randomly.
+func TagColors() []string {
+	return tagColors
+}
+
+// TagPaletteName returns the name of the active tag palette.
+func TagPaletteName() string {
+	return tagPaletteName
+}
+
+// SetTagColorsRestricted sets whether tags may only use colors from the active palette.
+// Restricting requires a palette with a fixed set of colors.
+func SetTagColorsRestricted(restricted bool) error {
+	if restricted && len(tagColors) == 0 {
+		return fmt.Errorf("tag palette '%s' has no fixed colors to restrict tags to", tagPaletteName)
+	}
+	tagColorsRestricted = restricted
+	return nil
+}
+
+// TagColorsRestricted reports whether tags may only use colors from the active palette.
+func TagColorsRestricted() bool {
+	return tagColorsRestricted
+}
+
+// ValidTagColor reports whether a tag may use the color, which is always the case unless
+// tag colors are restricted to the active palette. Colors are compared case-insensitively.
+func ValidTagColor(color string) bool {
+	if !tagColorsRestricted {
+		return true
+	}
+	for _, paletteColor := range tagColors {
+		if strings.EqualFold(color, paletteColor) {
+			return true
+		}
+	}
+	return false
+}
@@ -81,3 +81,50 @@ func TestTagCreation(t *testing.T) {
 		t.Errorf("Expected color '#FF0000', got %s", retrievedTag.Color)
 	}
 }
+
+func TestSetTagPalette(t *testing.T) {
+	t.Cleanup(func() { SetTagPalette(TagPaletteDefault) })
+
+	if err := SetTagPalette(TagPaletteColorblind); err != nil {
+		t.Fatalf("SetTagPalette failed: %v", err)
+	}
+	if len(TagColors()) == 0 {
+		t.Error("Expected colorblind palette to provide colors")
+	}
+
+	if err := SetTagPalette(TagPaletteDefault); err != nil {
+		t.Fatalf("SetTagPalette failed: %v", err)
+	}
+	if TagColors() != nil {
+		t.Error("Expected default palette to generate random colors")
+	}
+
+	if err := SetTagPalette("neon"); err == nil {
+		t.Error("Expected error for unknown palette")
+	}
+}
+
+func TestValidTagColor(t *testing.T) {
+	t.Cleanup(func() {
+		SetTagColorsRestricted(false)
+		SetTagPalette(TagPaletteDefault)
+	})
+
+	if !ValidTagColor("#123456") {
+		t.Error("Expected any color to be valid without a restriction")
+	}
+	if err := SetTagColorsRestricted(true); err == nil {
+		t.Error("Expected error restricting colors to the default palette")
+	}
+
+	SetTagPalette(TagPaletteColorblind)
+	if err := SetTagColorsRestricted(true); err != nil {
+		t.Fatalf("SetTagColorsRestricted failed: %v", err)
+	}
+	if !ValidTagColor("#E69F00") {
+		t.Error("Expected palette color to be valid regardless of case")
+	}
+	if ValidTagColor("#123456") {
+		t.Error("Expected off-palette color to be rejected")
+	}
+}
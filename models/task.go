@@ -2,6 +2,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,18 +11,38 @@ import (
 )
 
 // Task represents a daily task with optional frequency and tags.
+// UpdatedAt records when the task was last reset or completed, and the scheduler measures
+// the next reset from it, so bookkeeping changes such as streaks, reminders, priority
+// escalation and reordering use UpdateColumn to leave it alone.
 type Task struct {
-	ID          string     `json:"id" gorm:"type:text;primaryKey"`
-	Name        string     `json:"name" gorm:"not null"`
-	Description *string    `json:"description,omitempty"`
-	Completed   bool       `json:"completed" gorm:"default:false"`
-	Priority    *int       `json:"priority,omitempty" gorm:"check:priority >= 1 AND priority <= 5"`
-	FrequencyID *string    `json:"frequency_id,omitempty" gorm:"type:text"`
-	Frequency   *Frequency `json:"frequency,omitempty" gorm:"foreignKey:FrequencyID"`
-	Tags        []Tag      `json:"tags,omitempty" gorm:"many2many:task_tags;"`
-	Deleted     bool       `json:"deleted" gorm:"default:false"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID               string     `json:"id" gorm:"type:text;primaryKey"`
+	Name             string     `json:"name" gorm:"not null"`
+	Description      *string    `json:"description,omitempty"`
+	Completed        bool       `json:"completed" gorm:"default:false"`
+	Priority         *int       `json:"priority,omitempty" gorm:"check:priority >= 1"`
+	DueDate          *time.Time `json:"due_date,omitempty"`
+	EstimatedMinutes *int       `json:"estimated_minutes,omitempty" gorm:"check:estimated_minutes >= 0"`
+	FrequencyID      *string    `json:"frequency_id,omitempty" gorm:"type:text"`
+	RecurUntil       *time.Time `json:"recur_until,omitempty"`
+	Archived         bool       `json:"archived" gorm:"default:false"`
+	Frequency        *Frequency `json:"frequency,omitempty" gorm:"foreignKey:FrequencyID"`
+	Tags             []Tag      `json:"tags,omitempty" gorm:"many2many:task_tags;"`
+	ParentID         *string    `json:"parent_id,omitempty" gorm:"type:text;index"`
+	Subtasks         []Task     `json:"subtasks,omitempty" gorm:"foreignKey:ParentID"`
+	Position         int        `json:"position" gorm:"not null;default:0"`
+	CurrentStreak    int        `json:"current_streak" gorm:"not null;default:0"`
+	LongestStreak    int        `json:"longest_streak" gorm:"not null;default:0"`
+	SkipNextReset    bool       `json:"skip_next_reset" gorm:"not null;default:false"`
+	ResetCount       int        `json:"reset_count" gorm:"not null;default:0"`
+	LastReset        *time.Time `json:"last_reset,omitempty"`
+	RemindedAt       *time.Time `json:"reminded_at,omitempty"`
+	Source           string     `json:"source" gorm:"not null;default:api"`
+	NoteCount        int64      `json:"note_count" gorm:"-"`
+	DisplayColor     string     `json:"display_color" gorm:"-"`
+	Deleted          bool       `json:"deleted" gorm:"default:false"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
 // BeforeCreate is a GORM hook that generates a UUID for the task before creation.
@@ -30,3 +52,159 @@ func (t *Task) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// Task sources accepted by Task.Source, naming the entry point a task was created from.
+const (
+	// TaskSourceWeb marks tasks created from the web UI.
+	TaskSourceWeb = "web"
+	// TaskSourceMCP marks tasks created by an MCP agent.
+	TaskSourceMCP = "mcp"
+	// TaskSourceCLI marks tasks created from the command line.
+	TaskSourceCLI = "cli"
+	// TaskSourceAPI marks tasks created by any other API client.
+	TaskSourceAPI = "api"
+)
+
+// ValidTaskSource reports whether source is a supported task source.
+func ValidTaskSource(source string) bool {
+	switch source {
+	case TaskSourceWeb, TaskSourceMCP, TaskSourceCLI, TaskSourceAPI:
+		return true
+	}
+	return false
+}
+
+// DefaultDisplayColor is the neutral display color of tasks without tags.
+const DefaultDisplayColor = "#9e9e9e"
+
+// AfterFind is a GORM hook that fills in the display color once the task's tags are loaded.
+func (t *Task) AfterFind(tx *gorm.DB) error {
+	t.DisplayColor = t.ComputeDisplayColor()
+	return nil
+}
+
+// ComputeDisplayColor returns the color clients should use for the task: the color of
+// the tag whose name sorts first, ignoring case, so the result does not depend on the
+// order tags were loaded in. Tasks without tags use DefaultDisplayColor.
+func (t *Task) ComputeDisplayColor() string {
+	var first *Tag
+	for i := range t.Tags {
+		tag := &t.Tags[i]
+		if tag.Color == "" {
+			continue
+		}
+		if first == nil || strings.ToLower(tag.Name) < strings.ToLower(first.Name) {
+			first = tag
+		}
+	}
+	if first == nil {
+		return DefaultDisplayColor
+	}
+	return first.Color
+}
+
+// NotificationsMuted reports whether notifications for the task should be suppressed,
+// which is the case when it has tags and all of them have notifications disabled.
+func (t *Task) NotificationsMuted() bool {
+	if len(t.Tags) == 0 {
+		return false
+	}
+	for _, tag := range t.Tags {
+		if tag.NotificationsEnabled {
+			return false
+		}
+	}
+	return true
+}
+
+// overdueGrace holds how long past its due date a task becomes overdue, selected at startup.
+var overdueGrace time.Duration
+
+// SetOverdueGrace sets how long after its due date an incomplete task is considered overdue.
+func SetOverdueGrace(grace time.Duration) {
+	overdueGrace = grace
+}
+
+// OverdueCutoff returns the due date before which incomplete tasks are overdue at now.
+func OverdueCutoff(now time.Time) time.Time {
+	return now.Add(-overdueGrace)
+}
+
+// DefaultPriorityLevels is the number of priority levels used unless configured otherwise.
+const DefaultPriorityLevels = 5
+
+// priorityLevels holds the highest valid priority, selected at startup.
+var priorityLevels = DefaultPriorityLevels
+
+// SetPriorityLevels sets the number of priority levels, so valid priorities range from 1 to levels.
+func SetPriorityLevels(levels int) error {
+	if levels < 2 {
+		return fmt.Errorf("priority levels must be at least 2, got %d", levels)
+	}
+	priorityLevels = levels
+	return nil
+}
+
+// PriorityLevels returns the configured number of priority levels.
+func PriorityLevels() int {
+	return priorityLevels
+}
+
+// ValidPriority reports whether priority is within the configured range.
+func ValidPriority(priority int) bool {
+	return priority >= 1 && priority <= priorityLevels
+}
+
+// defaultPriority holds the priority given to new tasks created without one; zero means none.
+var defaultPriority int
+
+// SetDefaultPriority sets the priority given to new tasks created without one. Zero leaves
+// such tasks without a priority; any other value must be a valid priority, so the priority
+// levels must be configured first.
+func SetDefaultPriority(priority int) error {
+	if priority != 0 && !ValidPriority(priority) {
+		return fmt.Errorf("default priority must be between 1 and %d, got %d", priorityLevels, priority)
+	}
+	defaultPriority = priority
+	return nil
+}
+
+// DefaultPriority returns the priority for a new task created without one, or nil when
+// no default is configured.
+func DefaultPriority() *int {
+	if defaultPriority == 0 {
+		return nil
+	}
+	priority := defaultPriority
+	return &priority
+}
+
+// TasksDueForReset returns the completed recurring tasks the scheduler resets at now:
+// tasks that are not deleted or archived, belong to an enabled frequency, and whose next
+// reset after they were last updated, honoring the frequency's reset boundary, is not
+// after now. Tasks whose frequency has an invalid cron expression are skipped. Tasks are
+// returned by name with their frequency and tags loaded.
+func TasksDueForReset(db *gorm.DB, now time.Time, timezone string) ([]Task, error) {
+	var tasks []Task
+	if err := db.Preload("Frequency").Preload("Tags").
+		Where("completed = ? AND frequency_id IS NOT NULL AND deleted = ? AND archived = ?", true, false, false).
+		Order("name").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	due := []Task{}
+	for _, task := range tasks {
+		// Tasks of paused frequencies stay completed until the frequency is resumed
+		if task.Frequency == nil || !task.Frequency.Enabled {
+			continue
+		}
+
+		nextReset, err := task.Frequency.NextReset(task.UpdatedAt, timezone)
+		if err != nil || nextReset.IsZero() || nextReset.After(now) {
+			continue
+		}
+		due = append(due, task)
+	}
+	return due, nil
+}
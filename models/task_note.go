@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskNote represents a timestamped progress note appended to a task's log.
+type TaskNote struct {
+	ID        string    `json:"id" gorm:"type:text;primaryKey"`
+	TaskID    string    `json:"task_id" gorm:"type:text;not null;index"`
+	Body      string    `json:"body" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook that generates a UUID for the note before creation.
+func (n *TaskNote) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskResetEvent records a single reset of a recurring task by the scheduler.
+type TaskResetEvent struct {
+	ID          string    `json:"id" gorm:"type:text;primaryKey"`
+	TaskID      string    `json:"task_id" gorm:"type:text;not null;index"`
+	FrequencyID string    `json:"frequency_id" gorm:"type:text;not null"`
+	ResetAt     time.Time `json:"reset_at" gorm:"not null"`
+}
+
+// BeforeCreate is a GORM hook that generates a UUID for the reset event before creation.
+func (e *TaskResetEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/driver/sqlite"
@@ -14,7 +15,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
 
-	err = db.AutoMigrate(&Task{}, &Frequency{}, &Tag{})
+	err = db.AutoMigrate(&Task{}, &Frequency{}, &Tag{}, &TaskNote{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -109,3 +110,147 @@ func stringPtr(s string) *string {
 func intPtr(i int) *int {
 	return &i
 }
+
+func TestSetPriorityLevels(t *testing.T) {
+	t.Cleanup(func() { SetPriorityLevels(DefaultPriorityLevels) })
+
+	if err := SetPriorityLevels(1); err == nil {
+		t.Error("Expected an error for fewer than 2 priority levels")
+	}
+
+	if err := SetPriorityLevels(3); err != nil {
+		t.Fatalf("Failed to set priority levels: %v", err)
+	}
+
+	for priority, valid := range map[int]bool{0: false, 1: true, 3: true, 4: false} {
+		if ValidPriority(priority) != valid {
+			t.Errorf("Expected ValidPriority(%d) to be %v under a 1-3 scale", priority, valid)
+		}
+	}
+}
+
+func TestTaskNotificationsMuted(t *testing.T) {
+	muted := Tag{Name: "muted", NotificationsEnabled: false}
+	loud := Tag{Name: "loud", NotificationsEnabled: true}
+
+	tests := []struct {
+		name     string
+		tags     []Tag
+		expected bool
+	}{
+		{"No tags", nil, false},
+		{"Only muted tag", []Tag{muted}, true},
+		{"Muted and unmuted tags", []Tag{muted, loud}, false},
+		{"Only unmuted tag", []Tag{loud}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := Task{Name: "Reminder", Tags: tt.tags}
+			if task.NotificationsMuted() != tt.expected {
+				t.Errorf("Expected NotificationsMuted() to be %v", tt.expected)
+			}
+		})
+	}
+}
+
+func TestTaskComputeDisplayColor(t *testing.T) {
+	work := Tag{Name: "work", Color: "#ff0000"}
+	home := Tag{Name: "Home", Color: "#00ff00"}
+
+	tests := []struct {
+		name     string
+		tags     []Tag
+		expected string
+	}{
+		{"No tags", nil, DefaultDisplayColor},
+		{"Single tag", []Tag{work}, "#ff0000"},
+		{"First tag by name", []Tag{work, home}, "#00ff00"},
+		{"Order independent", []Tag{home, work}, "#00ff00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := Task{Name: "Chores", Tags: tt.tags}
+			if got := task.ComputeDisplayColor(); got != tt.expected {
+				t.Errorf("Expected display color %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTaskDisplayColorAfterFind(t *testing.T) {
+	db := setupTestDB(t)
+
+	tag := Tag{Name: "Work", Color: "#123456"}
+	db.Create(&tag)
+	task := Task{Name: "Report", Tags: []Tag{tag}}
+	db.Create(&task)
+
+	var fetched Task
+	db.Preload("Tags").First(&fetched, "id = ?", task.ID)
+	if fetched.DisplayColor != "#123456" {
+		t.Errorf("Expected display color from tag, got %s", fetched.DisplayColor)
+	}
+}
+
+func TestSetDefaultPriority(t *testing.T) {
+	defer SetDefaultPriority(0)
+
+	if err := SetDefaultPriority(DefaultPriorityLevels + 1); err == nil {
+		t.Error("Expected an error for a default priority outside the priority levels")
+	}
+
+	if err := SetDefaultPriority(0); err != nil || DefaultPriority() != nil {
+		t.Errorf("Expected no default priority, got %v (err %v)", DefaultPriority(), err)
+	}
+
+	if err := SetDefaultPriority(3); err != nil {
+		t.Fatalf("Failed to set default priority: %v", err)
+	}
+	if priority := DefaultPriority(); priority == nil || *priority != 3 {
+		t.Errorf("Expected default priority 3, got %v", priority)
+	}
+}
+
+func TestTasksDueForReset(t *testing.T) {
+	db := setupTestDB(t)
+
+	daily := Frequency{Name: "Daily", Period: "0 0 * * *"}
+	paused := Frequency{Name: "Paused", Period: "0 0 * * *"}
+	broken := Frequency{Name: "Broken", Period: "every day"}
+	for _, f := range []*Frequency{&daily, &paused, &broken} {
+		if err := db.Create(f).Error; err != nil {
+			t.Fatalf("Failed to create frequency: %v", err)
+		}
+	}
+	db.Model(&paused).Update("enabled", false)
+
+	now := time.Now()
+	twoDaysAgo := now.Add(-48 * time.Hour)
+	for _, task := range []Task{
+		{Name: "Due", Completed: true, FrequencyID: &daily.ID, UpdatedAt: twoDaysAgo},
+		{Name: "Also due", Completed: true, FrequencyID: &daily.ID, UpdatedAt: twoDaysAgo, SkipNextReset: true},
+		{Name: "Not yet", Completed: true, FrequencyID: &daily.ID},
+		{Name: "Incomplete", FrequencyID: &daily.ID, UpdatedAt: twoDaysAgo},
+		{Name: "Archived", Completed: true, Archived: true, FrequencyID: &daily.ID, UpdatedAt: twoDaysAgo},
+		{Name: "Paused", Completed: true, FrequencyID: &paused.ID, UpdatedAt: twoDaysAgo},
+		{Name: "Broken", Completed: true, FrequencyID: &broken.ID, UpdatedAt: twoDaysAgo},
+		{Name: "One-off", Completed: true, UpdatedAt: twoDaysAgo},
+	} {
+		if err := db.Create(&task).Error; err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	tasks, err := TasksDueForReset(db, now, "UTC")
+	if err != nil {
+		t.Fatalf("Failed to select tasks: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].Name != "Also due" || tasks[1].Name != "Due" {
+		t.Fatalf("Expected 'Also due' and 'Due', got %+v", tasks)
+	}
+	if tasks[0].Frequency == nil || tasks[0].Frequency.ID != daily.ID {
+		t.Error("Expected tasks to be returned with their frequency")
+	}
+}
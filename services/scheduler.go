@@ -3,32 +3,50 @@ package services
 
 import (
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
 	"gorm.io/gorm"
 
+	"github.com/jhoffmann/dailies/metrics"
 	"github.com/jhoffmann/dailies/models"
 )
 
 // TaskScheduler manages background task resets based on frequency schedules.
-// It runs a single cron job every minute that checks all completed tasks with frequencies
-// and resets them if their scheduled reset time has passed.
+// It runs a single cron job every minute, or every second when cron seconds are enabled,
+// that checks all completed tasks with frequencies and resets them if their scheduled
+// reset time has passed.
 type TaskScheduler struct {
-	db        *gorm.DB
-	cron      *cron.Cron
-	wsManager *WebSocketManager
-	location  *time.Location
-	timezone  string
+	db             *gorm.DB
+	cron           *cron.Cron
+	checkSpec      string
+	wsManager      *WebSocketManager
+	location       *time.Location
+	timezone       string
+	trashRetention time.Duration
+	webhook        *WebhookNotifier
+	escalationAge  time.Duration
+	escalateAll    bool
+	running        atomic.Bool
 }
 
 // NewTaskScheduler creates a new task scheduler instance with the provided database connection and timezone.
+// The reset check runs at the cron precision configured in the models package.
 func NewTaskScheduler(db *gorm.DB, location *time.Location, timezone string) *TaskScheduler {
+	options := []cron.Option{cron.WithLocation(location)}
+	checkSpec := "* * * * *"
+	if models.CronSeconds() {
+		options = append(options, cron.WithSeconds())
+		checkSpec = "* * * * * *"
+	}
+
 	return &TaskScheduler{
-		db:       db,
-		cron:     cron.New(cron.WithLocation(location)),
-		location: location,
-		timezone: timezone,
+		db:        db,
+		cron:      cron.New(options...),
+		checkSpec: checkSpec,
+		location:  location,
+		timezone:  timezone,
 	}
 }
 
@@ -37,12 +55,32 @@ func (ts *TaskScheduler) SetWebSocketManager(wsManager *WebSocketManager) {
 	ts.wsManager = wsManager
 }
 
-// Start begins the background scheduler that checks for task resets every minute.
+// SetTrashRetention sets how long soft deleted tasks and tags are kept before they
+// are permanently removed. A zero duration disables purging.
+func (ts *TaskScheduler) SetTrashRetention(retention time.Duration) {
+	ts.trashRetention = retention
+}
+
+// SetPriorityEscalation enables a daily job that raises the priority of incomplete tasks
+// created longer ago than age by one level. Only tasks with an auto-escalating tag are
+// affected unless allTasks is set. A zero age disables escalation.
+func (ts *TaskScheduler) SetPriorityEscalation(age time.Duration, allTasks bool) {
+	ts.escalationAge = age
+	ts.escalateAll = allTasks
+}
+
+// SetWebhookNotifier sets the notifier that receives a payload for every task reset.
+func (ts *TaskScheduler) SetWebhookNotifier(webhook *WebhookNotifier) {
+	ts.webhook = webhook
+}
+
+// Start begins the background scheduler that checks for task resets every minute, or
+// every second when cron seconds are enabled.
 // This approach is fully dynamic - it automatically handles tasks and frequencies
 // created after the service starts without requiring restart or reconfiguration.
 func (ts *TaskScheduler) Start() {
-	// Check every minute for tasks that need to be reset
-	_, err := ts.cron.AddFunc("* * * * *", func() {
+	// Check every minute (or second) for tasks that need to be reset
+	_, err := ts.cron.AddFunc(ts.checkSpec, func() {
 		ts.resetCompletedTasks()
 	})
 	if err != nil {
@@ -50,16 +88,45 @@ func (ts *TaskScheduler) Start() {
 		return
 	}
 
+	// Purge expired trash hourly when a retention period is configured
+	if ts.trashRetention > 0 {
+		_, err := ts.cron.AddFunc("@hourly", func() {
+			ts.purgeDeletedRecords()
+		})
+		if err != nil {
+			log.Printf("Failed to schedule trash purge job: %v", err)
+			return
+		}
+	}
+
+	// Escalate neglected tasks daily when an escalation age is configured
+	if ts.escalationAge > 0 {
+		_, err := ts.cron.AddFunc("@daily", func() {
+			ts.escalatePriorities()
+		})
+		if err != nil {
+			log.Printf("Failed to schedule priority escalation job: %v", err)
+			return
+		}
+	}
+
 	ts.cron.Start()
+	ts.running.Store(true)
 	log.Println("Task scheduler started")
 }
 
 // Stop stops the background scheduler gracefully.
 func (ts *TaskScheduler) Stop() {
 	ts.cron.Stop()
+	ts.running.Store(false)
 	log.Println("Task scheduler stopped")
 }
 
+// Running reports whether the scheduler was started successfully and has not been stopped.
+func (ts *TaskScheduler) Running() bool {
+	return ts.running.Load()
+}
+
 // GetTimezone returns the configured timezone name.
 func (ts *TaskScheduler) GetTimezone() string {
 	return ts.timezone
@@ -71,63 +138,106 @@ func (ts *TaskScheduler) GetLocation() *time.Location {
 }
 
 // resetCompletedTasks checks all completed tasks with frequencies and resets them
-// if their scheduled reset time has passed. This method runs every minute and handles
+// if their scheduled reset time has passed. This method runs every check interval and handles
 // all frequency-based task resets dynamically.
 func (ts *TaskScheduler) resetCompletedTasks() {
-	var tasks []models.Task
+	// Tasks past their recurrence end are archived rather than reset
+	ts.archiveExpiredTasks()
+
+	// Streaks end for tasks that were left incomplete through a reset
+	ts.breakMissedStreaks()
 
-	// Get all completed tasks that have frequencies and are not deleted
-	result := ts.db.Preload("Frequency").
-		Where("completed = ? AND frequency_id IS NOT NULL AND deleted = ?", true, false).
-		Find(&tasks)
+	// Incomplete tasks are reminded shortly before their frequency fires
+	ts.remindIncompleteTasks()
 
-	if result.Error != nil {
-		log.Printf("Error fetching tasks for reset check: %v", result.Error)
+	now := time.Now().In(ts.location)
+	tasks, err := models.TasksDueForReset(ts.db, now, ts.timezone)
+	if err != nil {
+		log.Printf("Error fetching tasks for reset check: %v", err)
 		return
 	}
 
-	now := time.Now().In(ts.location)
 	resetCount := 0
-
 	for _, task := range tasks {
-		if task.Frequency == nil {
+		// A skipped reset clears the flag instead, keeping the task completed for another
+		// period since the next reset is calculated from the updated time
+		if task.SkipNextReset {
+			if err := ts.db.Model(&task).Update("skip_next_reset", false).Error; err != nil {
+				log.Printf("Error skipping reset of task %s: %v", task.Name, err)
+			} else {
+				log.Printf("Skipped reset of task '%s' (frequency: %s)", task.Name, task.Frequency.Name)
+			}
 			continue
 		}
 
-		// Parse the 5-field cron expression (format: "minute hour day month day-of-week")
-		parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+		// Saving the task upserts its preloaded tags, which resets their defaults in
+		// memory, so check for muted notifications first
+		muted := task.NotificationsMuted()
 
-		// Prefix the Period with timezone to ensure it uses the correct timezone
-		schedule, err := parser.Parse("TZ=" + ts.timezone + " " + task.Frequency.Period)
+		// Completing the task within the period extends its streak, and the reset is
+		// recorded in the task's history. In clone mode the completed task is archived
+		// and an incomplete copy takes over its streak and history
+		streak := task.CurrentStreak + 1
+		resetID := task.ID
+		err := ts.db.Transaction(func(tx *gorm.DB) error {
+			counters := map[string]any{
+				"completed":      false,
+				"current_streak": streak,
+				"longest_streak": max(task.LongestStreak, streak),
+				"reset_count":    task.ResetCount + 1,
+				"last_reset":     now,
+			}
+			if task.Frequency.ResetMode == models.ResetModeClone {
+				clone, err := cloneCompletedTask(tx, task)
+				if err != nil {
+					return err
+				}
+				resetID = clone.ID
+				if err := tx.Model(&clone).Updates(counters).Error; err != nil {
+					return err
+				}
+			} else if err := tx.Model(&task).Updates(counters).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.TaskResetEvent{
+				TaskID:      resetID,
+				FrequencyID: task.Frequency.ID,
+				ResetAt:     now,
+			}).Error
+		})
 		if err != nil {
-			log.Printf("Invalid cron expression '%s' for task %s: %v",
-				task.Frequency.Period, task.Name, err)
+			log.Printf("Error resetting task %s: %v", task.Name, err)
 			continue
 		}
+		resetCount++
+		metrics.TasksReset.Inc()
 
-		// Calculate when this task should next reset after it was completed
-		// The task should only reset after the next scheduled reset time following completion
-		nextReset := schedule.Next(task.UpdatedAt)
-
-		// If the scheduled reset time has passed, reset the task
-		if nextReset.Before(now) || nextReset.Equal(now) {
-			err := ts.db.Model(&task).Update("completed", false).Error
-			if err != nil {
-				log.Printf("Error resetting task %s: %v", task.Name, err)
-				continue
-			}
-			resetCount++
+		log.Printf("Reset task '%s' (frequency: %s)", task.Name, task.Frequency.Name)
 
-			log.Printf("Reset task '%s' (frequency: %s)", task.Name, task.Frequency.Name)
+		// Notify the webhook unless all of the task's tags are muted
+		if ts.webhook != nil && !muted {
+			ts.webhook.Notify(TaskResetPayload{
+				Event:         string(EventTaskReset),
+				TaskID:        resetID,
+				Name:          task.Name,
+				FrequencyID:   task.Frequency.ID,
+				FrequencyName: task.Frequency.Name,
+				ResetAt:       now,
+			})
+		}
 
-			// Broadcast the task reset event
-			if ts.wsManager != nil {
-				// Reload the task to get the latest state for broadcasting
-				var updatedTask models.Task
+		// Broadcast the task reset event, along with the archived original of a copy
+		if ts.wsManager != nil {
+			// Reload the tasks to get the latest state for broadcasting
+			var updatedTask models.Task
+			if resetID != task.ID {
 				if err := ts.db.Preload("Tags").Preload("Frequency").First(&updatedTask, "id = ?", task.ID).Error; err == nil {
-					ts.wsManager.Broadcast(EventTaskReset, updatedTask)
+					ts.wsManager.Broadcast(EventTaskUpdate, updatedTask)
 				}
 			}
+			if err := ts.db.Preload("Tags").Preload("Frequency").First(&updatedTask, "id = ?", resetID).Error; err == nil {
+				ts.wsManager.Broadcast(EventTaskReset, updatedTask)
+			}
 		}
 	}
 
@@ -135,3 +245,257 @@ func (ts *TaskScheduler) resetCompletedTasks() {
 		log.Printf("Reset %d tasks", resetCount)
 	}
 }
+
+// cloneCompletedTask archives a completed task, keeping it as history, and creates an
+// incomplete copy with the same name, description, priority, estimate, frequency,
+// recurrence end, parent, source and tags. The live subtasks move to the copy and are
+// made incomplete, and earlier reset events move with them so the copy's history is
+// complete. Due dates stay with the original.
+func cloneCompletedTask(tx *gorm.DB, task models.Task) (models.Task, error) {
+	clone := models.Task{
+		Name:             task.Name,
+		Description:      task.Description,
+		Priority:         task.Priority,
+		EstimatedMinutes: task.EstimatedMinutes,
+		FrequencyID:      task.FrequencyID,
+		RecurUntil:       task.RecurUntil,
+		ParentID:         task.ParentID,
+		Source:           task.Source,
+		Tags:             task.Tags,
+	}
+	// Only the associations are written, leaving the tags themselves untouched
+	if err := tx.Omit("Tags.*").Create(&clone).Error; err != nil {
+		return models.Task{}, err
+	}
+	if err := tx.Model(&models.Task{}).
+		Where("parent_id = ? AND deleted = ?", task.ID, false).
+		Updates(map[string]any{"parent_id": clone.ID, "completed": false}).Error; err != nil {
+		return models.Task{}, err
+	}
+	if err := tx.Model(&models.TaskResetEvent{}).
+		Where("task_id = ?", task.ID).
+		Update("task_id", clone.ID).Error; err != nil {
+		return models.Task{}, err
+	}
+	if err := tx.Model(&task).Update("archived", true).Error; err != nil {
+		return models.Task{}, err
+	}
+	return clone, nil
+}
+
+// breakMissedStreaks zeroes the streak of every incomplete recurring task whose frequency
+// has reset since the task was last reset or updated, meaning a period passed without it
+// being completed.
+func (ts *TaskScheduler) breakMissedStreaks() {
+	var tasks []models.Task
+	if err := ts.db.Preload("Frequency").
+		Where("completed = ? AND frequency_id IS NOT NULL AND deleted = ? AND archived = ? AND current_streak > 0", false, false, false).
+		Find(&tasks).Error; err != nil {
+		log.Printf("Error fetching tasks for streak check: %v", err)
+		return
+	}
+
+	now := time.Now().In(ts.location)
+	for _, task := range tasks {
+		if task.Frequency == nil {
+			continue
+		}
+
+		deadline, err := task.Frequency.NextReset(task.UpdatedAt, ts.timezone)
+		if err != nil || deadline.IsZero() || deadline.After(now) {
+			continue
+		}
+
+		if err := ts.db.Model(&task).UpdateColumn("current_streak", 0).Error; err != nil {
+			log.Printf("Error resetting streak of task %s: %v", task.Name, err)
+			continue
+		}
+
+		log.Printf("Streak of %d ended for task '%s'", task.CurrentStreak, task.Name)
+	}
+}
+
+// remindIncompleteTasks broadcasts a reminder, and notifies the webhook, for every
+// incomplete task whose frequency fires within its reminder lead. Each task is reminded
+// at most once per period, and tasks whose tags all have notifications disabled are
+// not reminded.
+func (ts *TaskScheduler) remindIncompleteTasks() {
+	remindingFrequencies := ts.db.Model(&models.Frequency{}).Select("id").
+		Where("enabled = ? AND reminder_lead_minutes IS NOT NULL", true)
+
+	var tasks []models.Task
+	if err := ts.db.Preload("Frequency").Preload("Tags").
+		Where("completed = ? AND deleted = ? AND archived = ? AND frequency_id IN (?)", false, false, false, remindingFrequencies).
+		Find(&tasks).Error; err != nil {
+		log.Printf("Error fetching tasks for reminders: %v", err)
+		return
+	}
+
+	now := time.Now().In(ts.location)
+	for _, task := range tasks {
+		if task.Frequency == nil || task.Frequency.ReminderLead == nil || task.NotificationsMuted() {
+			continue
+		}
+
+		schedule, err := task.Frequency.Schedule(ts.timezone)
+		if err != nil {
+			continue
+		}
+		nextReset := schedule.Next(now)
+		if nextReset.IsZero() {
+			continue
+		}
+
+		// Reminders sent since the window opened belong to the current period
+		windowStart := nextReset.Add(-time.Duration(*task.Frequency.ReminderLead) * time.Minute)
+		if now.Before(windowStart) || (task.RemindedAt != nil && !task.RemindedAt.Before(windowStart)) {
+			continue
+		}
+
+		if err := ts.db.Model(&task).UpdateColumn("reminded_at", now).Error; err != nil {
+			log.Printf("Error recording reminder for task %s: %v", task.Name, err)
+			continue
+		}
+
+		log.Printf("Reminded task '%s' before its reset at %s", task.Name, nextReset.Format(time.Kitchen))
+
+		if ts.webhook != nil {
+			ts.webhook.Notify(TaskResetPayload{
+				Event:         string(EventTaskReminder),
+				TaskID:        task.ID,
+				Name:          task.Name,
+				FrequencyID:   task.Frequency.ID,
+				FrequencyName: task.Frequency.Name,
+				ResetAt:       nextReset,
+			})
+		}
+
+		if ts.wsManager != nil {
+			ts.wsManager.Broadcast(EventTaskReminder, task)
+		}
+	}
+}
+
+// archiveExpiredTasks archives every recurring task whose recurrence end date has passed,
+// so the scheduler stops resetting it even though its frequency keeps firing. Tasks
+// without a frequency are left alone, even when they kept a recurrence end date.
+func (ts *TaskScheduler) archiveExpiredTasks() {
+	var tasks []models.Task
+	if err := ts.db.Where("deleted = ? AND archived = ? AND frequency_id IS NOT NULL AND recur_until IS NOT NULL AND recur_until < ?", false, false, time.Now()).
+		Find(&tasks).Error; err != nil {
+		log.Printf("Error fetching tasks past their recurrence end: %v", err)
+		return
+	}
+
+	for _, task := range tasks {
+		if err := ts.db.Model(&task).Update("archived", true).Error; err != nil {
+			log.Printf("Error archiving task %s: %v", task.Name, err)
+			continue
+		}
+
+		log.Printf("Archived task '%s' after its recurrence ended", task.Name)
+
+		if ts.wsManager != nil {
+			var updatedTask models.Task
+			if err := ts.db.Preload("Tags").Preload("Frequency").First(&updatedTask, "id = ?", task.ID).Error; err == nil {
+				ts.wsManager.Broadcast(EventTaskUpdate, updatedTask)
+			}
+		}
+	}
+}
+
+// escalatePriorities raises the priority of every incomplete task left undone for longer
+// than the configured escalation age by one level, never going above priority 1. Time
+// undone is measured from the last reset, or from creation for tasks never reset, so
+// recurring tasks completed every period do not escalate.
+func (ts *TaskScheduler) escalatePriorities() {
+	if ts.escalationAge <= 0 {
+		return
+	}
+
+	query := ts.db.Where("completed = ? AND deleted = ? AND archived = ? AND priority > 1 AND COALESCE(last_reset, created_at) < ?",
+		false, false, false, time.Now().Add(-ts.escalationAge))
+	if !ts.escalateAll {
+		escalatingTasks := ts.db.Table("task_tags").Select("task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("tags.auto_escalate = ? AND tags.deleted_at IS NULL", true)
+		query = query.Where("id IN (?)", escalatingTasks)
+	}
+
+	var tasks []models.Task
+	if err := query.Find(&tasks).Error; err != nil {
+		log.Printf("Error fetching tasks for priority escalation: %v", err)
+		return
+	}
+
+	for _, task := range tasks {
+		priority := *task.Priority - 1
+		if err := ts.db.Model(&task).UpdateColumn("priority", priority).Error; err != nil {
+			log.Printf("Error escalating task %s: %v", task.Name, err)
+			continue
+		}
+
+		log.Printf("Escalated task '%s' to priority %d", task.Name, priority)
+
+		if ts.wsManager != nil {
+			var updatedTask models.Task
+			if err := ts.db.Preload("Tags").Preload("Frequency").First(&updatedTask, "id = ?", task.ID).Error; err == nil {
+				ts.wsManager.Broadcast(EventTaskUpdate, updatedTask)
+			}
+		}
+	}
+}
+
+// purgeDeletedRecords permanently removes tasks and tags that were soft deleted
+// longer ago than the configured trash retention, along with their tag associations.
+func (ts *TaskScheduler) purgeDeletedRecords() {
+	if ts.trashRetention <= 0 {
+		return
+	}
+
+	cutoff := time.Now().Add(-ts.trashRetention)
+	var tasksPurged, tagsPurged int64
+
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		// Tasks deleted before deletion timestamps were recorded fall back to their last update
+		expiredTasks := tx.Model(&models.Task{}).Select("id").
+			Where("deleted = ? AND COALESCE(deleted_at, updated_at) < ?", true, cutoff)
+		if err := tx.Exec("DELETE FROM task_tags WHERE task_id IN (?)", expiredTasks).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id IN (?)", expiredTasks).Delete(&models.TaskNote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id IN (?)", expiredTasks).Delete(&models.SnapshotTask{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id IN (?)", expiredTasks).Delete(&models.TaskResetEvent{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("deleted = ? AND COALESCE(deleted_at, updated_at) < ?", true, cutoff).Delete(&models.Task{})
+		if result.Error != nil {
+			return result.Error
+		}
+		tasksPurged = result.RowsAffected
+
+		expiredTags := tx.Unscoped().Model(&models.Tag{}).Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+		if err := tx.Exec("DELETE FROM task_tags WHERE tag_id IN (?)", expiredTags).Error; err != nil {
+			return err
+		}
+		result = tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&models.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		tagsPurged = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error purging deleted records: %v", err)
+		return
+	}
+
+	if tasksPurged > 0 || tagsPurged > 0 {
+		log.Printf("Purged %d tasks and %d tags from trash", tasksPurged, tagsPurged)
+	}
+}
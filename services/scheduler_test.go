@@ -1,12 +1,15 @@
 package services
 
 import (
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/jhoffmann/dailies/metrics"
 	"github.com/jhoffmann/dailies/models"
 )
 
@@ -16,7 +19,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
 
-	err = db.AutoMigrate(&models.Task{}, &models.Frequency{}, &models.Tag{})
+	err = db.AutoMigrate(&models.Task{}, &models.Frequency{}, &models.Tag{}, &models.TaskNote{}, &models.SnapshotTask{}, &models.TaskResetEvent{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -50,14 +53,37 @@ func TestNewTaskScheduler(t *testing.T) {
 	}
 }
 
+func TestNewTaskSchedulerWithCronSeconds(t *testing.T) {
+	models.SetCronSeconds(true)
+	t.Cleanup(func() { models.SetCronSeconds(false) })
+
+	scheduler, _ := setupTestScheduler(t)
+	if scheduler.checkSpec != "* * * * * *" {
+		t.Errorf("Expected resets to be checked every second, got %q", scheduler.checkSpec)
+	}
+	if _, err := scheduler.cron.AddFunc(scheduler.checkSpec, func() {}); err != nil {
+		t.Errorf("Expected the check schedule to be accepted: %v", err)
+	}
+}
+
 func TestTaskSchedulerStartStop(t *testing.T) {
 	scheduler, _ := setupTestScheduler(t)
 
+	if scheduler.Running() {
+		t.Error("Expected a new scheduler not to be running")
+	}
+
 	// Test start
 	scheduler.Start()
+	if !scheduler.Running() {
+		t.Error("Expected the scheduler to be running after Start")
+	}
 
 	// Test stop
 	scheduler.Stop()
+	if scheduler.Running() {
+		t.Error("Expected the scheduler not to be running after Stop")
+	}
 }
 
 func TestResetCompletedTasksWithValidCronExpression(t *testing.T) {
@@ -87,6 +113,7 @@ func TestResetCompletedTasksWithValidCronExpression(t *testing.T) {
 	}
 
 	// Run the reset function
+	resets := testutil.ToFloat64(metrics.TasksReset)
 	scheduler.resetCompletedTasks()
 
 	// Reload the task and check if it was reset
@@ -98,6 +125,9 @@ func TestResetCompletedTasksWithValidCronExpression(t *testing.T) {
 	if task.Completed {
 		t.Error("Expected task to be reset (completed=false), but it's still completed")
 	}
+	if delta := testutil.ToFloat64(metrics.TasksReset) - resets; delta != 1 {
+		t.Errorf("Expected reset counter to increase by 1, got %v", delta)
+	}
 }
 
 func TestResetCompletedTasksWithInvalidCronExpression(t *testing.T) {
@@ -314,3 +344,534 @@ func TestResetCompletedTasksWithHourlyFrequency(t *testing.T) {
 		t.Error("Expected hourly task to be reset after 2 hours")
 	}
 }
+
+func TestPurgeDeletedRecords(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+	scheduler.SetTrashRetention(7 * 24 * time.Hour)
+
+	longAgo := time.Now().Add(-10 * 24 * time.Hour)
+	recently := time.Now().Add(-24 * time.Hour)
+
+	expiredTask := &models.Task{Name: "Expired", Deleted: true, DeletedAt: &longAgo}
+	recentTask := &models.Task{Name: "Recent", Deleted: true, DeletedAt: &recently}
+	activeTask := &models.Task{Name: "Active"}
+	if err := db.Create([]*models.Task{expiredTask, recentTask, activeTask}).Error; err != nil {
+		t.Fatalf("Failed to create tasks: %v", err)
+	}
+
+	expiredTag := &models.Tag{Name: "Expired", Color: "#ff0000"}
+	recentTag := &models.Tag{Name: "Recent", Color: "#00ff00"}
+	if err := db.Create([]*models.Tag{expiredTag, recentTag}).Error; err != nil {
+		t.Fatalf("Failed to create tags: %v", err)
+	}
+	db.Model(activeTask).Association("Tags").Append(expiredTag)
+	db.Unscoped().Model(expiredTag).Update("deleted_at", longAgo)
+	db.Unscoped().Model(recentTag).Update("deleted_at", recently)
+
+	scheduler.purgeDeletedRecords()
+
+	var taskCount int64
+	db.Model(&models.Task{}).Where("id = ?", expiredTask.ID).Count(&taskCount)
+	if taskCount != 0 {
+		t.Error("Expected task deleted beyond retention to be purged")
+	}
+	db.Model(&models.Task{}).Where("id IN ?", []string{recentTask.ID, activeTask.ID}).Count(&taskCount)
+	if taskCount != 2 {
+		t.Errorf("Expected recent and active tasks to be retained, got %d", taskCount)
+	}
+
+	var tagCount int64
+	db.Unscoped().Model(&models.Tag{}).Where("id = ?", expiredTag.ID).Count(&tagCount)
+	if tagCount != 0 {
+		t.Error("Expected tag deleted beyond retention to be purged")
+	}
+	db.Unscoped().Model(&models.Tag{}).Where("id = ?", recentTag.ID).Count(&tagCount)
+	if tagCount != 1 {
+		t.Error("Expected recently deleted tag to be retained")
+	}
+
+	var associations int64
+	db.Table("task_tags").Where("tag_id = ?", expiredTag.ID).Count(&associations)
+	if associations != 0 {
+		t.Errorf("Expected purged tag associations to be removed, got %d", associations)
+	}
+}
+
+func TestResetCompletedTasksWithRecurUntil(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	frequency := &models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	if err := db.Create(frequency).Error; err != nil {
+		t.Fatalf("Failed to create frequency: %v", err)
+	}
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	nextWeek := time.Now().Add(7 * 24 * time.Hour)
+
+	ended := &models.Task{Name: "Ended", Completed: true, FrequencyID: &frequency.ID, RecurUntil: &yesterday, UpdatedAt: yesterday.Add(-time.Hour)}
+	ongoing := &models.Task{Name: "Ongoing", Completed: true, FrequencyID: &frequency.ID, RecurUntil: &nextWeek, UpdatedAt: yesterday.Add(-time.Hour)}
+	for _, task := range []*models.Task{ended, ongoing} {
+		if err := db.Create(task).Error; err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	scheduler.resetCompletedTasks()
+
+	db.First(ended, "id = ?", ended.ID)
+	if !ended.Completed {
+		t.Error("Expected task past its recurrence end not to be reset")
+	}
+	if !ended.Archived {
+		t.Error("Expected task past its recurrence end to be archived")
+	}
+
+	db.First(ongoing, "id = ?", ongoing.ID)
+	if ongoing.Completed {
+		t.Error("Expected task before its recurrence end to be reset")
+	}
+	if ongoing.Archived {
+		t.Error("Expected task before its recurrence end not to be archived")
+	}
+}
+
+func TestArchiveExpiredTasksSkipsNonRecurringTasks(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	// A task whose frequency was removed keeps its recurrence end date
+	yesterday := time.Now().Add(-24 * time.Hour)
+	oneOff := &models.Task{Name: "One-off", RecurUntil: &yesterday}
+	if err := db.Create(oneOff).Error; err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	scheduler.archiveExpiredTasks()
+
+	db.First(oneOff, "id = ?", oneOff.ID)
+	if oneOff.Archived {
+		t.Error("Expected task without a frequency not to be archived")
+	}
+}
+
+func TestEscalatePriorities(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+	scheduler.SetPriorityEscalation(72*time.Hour, true)
+
+	priority := 3
+	old := &models.Task{Name: "Old Task", Priority: &priority, CreatedAt: time.Now().Add(-96 * time.Hour)}
+	recent := &models.Task{Name: "Recent Task", Priority: &priority}
+	done := &models.Task{Name: "Done Task", Priority: &priority, Completed: true, CreatedAt: time.Now().Add(-96 * time.Hour)}
+	top := 1
+	urgent := &models.Task{Name: "Urgent Task", Priority: &top, CreatedAt: time.Now().Add(-96 * time.Hour)}
+	for _, task := range []*models.Task{old, recent, done, urgent} {
+		if err := db.Create(task).Error; err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	scheduler.escalatePriorities()
+
+	expected := map[string]int{old.ID: 2, recent.ID: 3, done.ID: 3, urgent.ID: 1}
+	for id, want := range expected {
+		var task models.Task
+		db.First(&task, "id = ?", id)
+		if task.Priority == nil || *task.Priority != want {
+			t.Errorf("Expected %s to have priority %d, got %v", task.Name, want, task.Priority)
+		}
+	}
+}
+
+func TestEscalatePrioritiesSkipsRecentlyResetTasks(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+	scheduler.SetPriorityEscalation(72*time.Hour, true)
+
+	frequency := &models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(frequency)
+
+	priority := 3
+	created := time.Now().Add(-30 * 24 * time.Hour)
+	recentReset := time.Now().Add(-12 * time.Hour)
+	staleReset := time.Now().Add(-96 * time.Hour)
+	habit := &models.Task{Name: "Daily Habit", Priority: &priority, FrequencyID: &frequency.ID, CreatedAt: created, LastReset: &recentReset}
+	neglected := &models.Task{Name: "Neglected Habit", Priority: &priority, FrequencyID: &frequency.ID, CreatedAt: created, LastReset: &staleReset}
+	db.Create(habit)
+	db.Create(neglected)
+
+	scheduler.escalatePriorities()
+
+	db.First(habit, "id = ?", habit.ID)
+	db.First(neglected, "id = ?", neglected.ID)
+	if *habit.Priority != 3 {
+		t.Errorf("Expected recently reset task to stay at priority 3, got %d", *habit.Priority)
+	}
+	if *neglected.Priority != 2 {
+		t.Errorf("Expected task undone since an old reset to escalate to priority 2, got %d", *neglected.Priority)
+	}
+}
+
+func TestEscalatePrioritiesOnlyForEscalatingTags(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+	scheduler.SetPriorityEscalation(72*time.Hour, false)
+
+	escalating := models.Tag{Name: "Chores", Color: "#ff0000", AutoEscalate: true}
+	db.Create(&escalating)
+
+	priority := 3
+	created := time.Now().Add(-96 * time.Hour)
+	tagged := &models.Task{Name: "Tagged Task", Priority: &priority, CreatedAt: created, Tags: []models.Tag{escalating}}
+	untagged := &models.Task{Name: "Untagged Task", Priority: &priority, CreatedAt: created}
+	db.Create(tagged)
+	db.Create(untagged)
+
+	scheduler.escalatePriorities()
+
+	db.First(tagged, "id = ?", tagged.ID)
+	db.First(untagged, "id = ?", untagged.ID)
+	if *tagged.Priority != 2 {
+		t.Errorf("Expected tagged task to escalate to priority 2, got %d", *tagged.Priority)
+	}
+	if *untagged.Priority != 3 {
+		t.Errorf("Expected untagged task to stay at priority 3, got %d", *untagged.Priority)
+	}
+}
+
+func TestEscalatePrioritiesKeepsMissedStreaks(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+	scheduler.SetPriorityEscalation(72*time.Hour, true)
+
+	frequency := &models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(frequency)
+
+	priority := 3
+	created := time.Now().Add(-96 * time.Hour)
+	twoDaysAgo := time.Now().Add(-48 * time.Hour)
+	task := &models.Task{Name: "Missed Task", Priority: &priority, FrequencyID: &frequency.ID, CurrentStreak: 3, LongestStreak: 3, CreatedAt: created, UpdatedAt: twoDaysAgo}
+	db.Create(task)
+
+	scheduler.escalatePriorities()
+
+	var escalated models.Task
+	db.First(&escalated, "id = ?", task.ID)
+	if *escalated.Priority != 2 {
+		t.Fatalf("Expected task to escalate to priority 2, got %d", *escalated.Priority)
+	}
+	if !escalated.UpdatedAt.Equal(task.UpdatedAt) {
+		t.Errorf("Expected escalation to leave updated_at at %v, got %v", task.UpdatedAt, escalated.UpdatedAt)
+	}
+
+	scheduler.breakMissedStreaks()
+
+	db.First(&escalated, "id = ?", task.ID)
+	if escalated.CurrentStreak != 0 {
+		t.Errorf("Expected the missed streak to be broken after escalation, got %d", escalated.CurrentStreak)
+	}
+}
+
+func TestResetCompletedTasksTracksStreaks(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	frequency := &models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(frequency)
+
+	twoDaysAgo := time.Now().Add(-48 * time.Hour)
+	completed := &models.Task{Name: "Completed Task", Completed: true, FrequencyID: &frequency.ID, CurrentStreak: 4, LongestStreak: 4, UpdatedAt: twoDaysAgo}
+	missed := &models.Task{Name: "Missed Task", FrequencyID: &frequency.ID, CurrentStreak: 3, LongestStreak: 5, UpdatedAt: twoDaysAgo}
+	pending := &models.Task{Name: "Pending Task", FrequencyID: &frequency.ID, CurrentStreak: 2, LongestStreak: 2}
+	for _, task := range []*models.Task{completed, missed, pending} {
+		if err := db.Create(task).Error; err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	scheduler.resetCompletedTasks()
+
+	expected := map[string][2]int{
+		completed.ID: {5, 5},
+		missed.ID:    {0, 5},
+		pending.ID:   {2, 2},
+	}
+	for id, streaks := range expected {
+		var task models.Task
+		db.First(&task, "id = ?", id)
+		if task.CurrentStreak != streaks[0] || task.LongestStreak != streaks[1] {
+			t.Errorf("Expected %s to have streak %d (longest %d), got %d (longest %d)",
+				task.Name, streaks[0], streaks[1], task.CurrentStreak, task.LongestStreak)
+		}
+	}
+}
+
+func TestResetCompletedTasksRecordsHistory(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	frequency := &models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(frequency)
+
+	task := &models.Task{Name: "Test Task", Completed: true, FrequencyID: &frequency.ID, ResetCount: 2, UpdatedAt: time.Now().Add(-48 * time.Hour)}
+	if err := db.Create(task).Error; err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	before := time.Now()
+	scheduler.resetCompletedTasks()
+
+	var updated models.Task
+	db.First(&updated, "id = ?", task.ID)
+	if updated.ResetCount != 3 {
+		t.Errorf("Expected reset count 3, got %d", updated.ResetCount)
+	}
+	if updated.LastReset == nil || updated.LastReset.Before(before) {
+		t.Errorf("Expected last reset to be set to the reset time, got %v", updated.LastReset)
+	}
+
+	var events []models.TaskResetEvent
+	db.Where("task_id = ?", task.ID).Find(&events)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 reset event, got %d", len(events))
+	}
+	if events[0].FrequencyID != frequency.ID || events[0].ResetAt.Before(before) {
+		t.Errorf("Unexpected reset event: %+v", events[0])
+	}
+}
+
+func TestResetCompletedTasksCloneMode(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	frequency := &models.Frequency{Name: "Daily", Period: "0 0 * * *", ResetMode: models.ResetModeClone}
+	db.Create(frequency)
+
+	tag := models.Tag{Name: "Home", Color: "#00ff00"}
+	db.Create(&tag)
+
+	priority := 2
+	task := &models.Task{
+		Name:          "Water plants",
+		Completed:     true,
+		Priority:      &priority,
+		FrequencyID:   &frequency.ID,
+		CurrentStreak: 4,
+		Tags:          []models.Tag{tag},
+		UpdatedAt:     time.Now().Add(-48 * time.Hour),
+	}
+	if err := db.Create(task).Error; err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	scheduler.resetCompletedTasks()
+
+	var original models.Task
+	db.First(&original, "id = ?", task.ID)
+	if !original.Completed || !original.Archived {
+		t.Errorf("Expected the completed task to be archived as history, got completed=%v archived=%v", original.Completed, original.Archived)
+	}
+
+	var clones []models.Task
+	db.Preload("Tags").Where("id <> ?", task.ID).Find(&clones)
+	if len(clones) != 1 {
+		t.Fatalf("Expected 1 new task, got %d", len(clones))
+	}
+	clone := clones[0]
+	if clone.Completed || clone.Archived || clone.Name != "Water plants" {
+		t.Errorf("Expected an incomplete copy of the task, got %+v", clone)
+	}
+	if clone.Priority == nil || *clone.Priority != 2 || clone.FrequencyID == nil || *clone.FrequencyID != frequency.ID {
+		t.Errorf("Expected the copy to keep priority and frequency, got %+v", clone)
+	}
+	if len(clone.Tags) != 1 || clone.Tags[0].ID != tag.ID {
+		t.Errorf("Expected the copy to keep the task's tags, got %+v", clone.Tags)
+	}
+	if clone.CurrentStreak != 5 || clone.ResetCount != 1 {
+		t.Errorf("Expected the copy to carry the streak and reset count, got streak %d and count %d", clone.CurrentStreak, clone.ResetCount)
+	}
+
+	var events int64
+	db.Model(&models.TaskResetEvent{}).Where("task_id = ?", clone.ID).Count(&events)
+	if events != 1 {
+		t.Errorf("Expected the reset to be recorded for the copy, got %d events", events)
+	}
+
+	// The archived original is never reset again
+	scheduler.resetCompletedTasks()
+	var count int64
+	db.Model(&models.Task{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected no further copies, got %d tasks", count)
+	}
+}
+
+func TestResetCompletedTasksCloneModeKeepsSubtasksAndHistory(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	frequency := &models.Frequency{Name: "Weekly", Period: "0 0 * * 0", ResetMode: models.ResetModeClone}
+	db.Create(frequency)
+
+	task := &models.Task{Name: "Pack gym bag", Completed: true, FrequencyID: &frequency.ID, UpdatedAt: time.Now().Add(-8 * 24 * time.Hour)}
+	db.Create(task)
+	towel := &models.Task{Name: "Towel", Completed: true, ParentID: &task.ID}
+	shoes := &models.Task{Name: "Shoes", ParentID: &task.ID}
+	trashed := &models.Task{Name: "Old item", Completed: true, ParentID: &task.ID, Deleted: true}
+	for _, subtask := range []*models.Task{towel, shoes, trashed} {
+		db.Create(subtask)
+	}
+	earlier := &models.TaskResetEvent{TaskID: task.ID, FrequencyID: frequency.ID, ResetAt: time.Now().Add(-15 * 24 * time.Hour)}
+	db.Create(earlier)
+
+	scheduler.resetCompletedTasks()
+
+	var clone models.Task
+	if err := db.Preload("Subtasks").Where("name = ? AND archived = ?", "Pack gym bag", false).First(&clone).Error; err != nil {
+		t.Fatalf("Expected an incomplete copy of the task: %v", err)
+	}
+	if len(clone.Subtasks) != 2 {
+		t.Fatalf("Expected the copy to take over 2 live subtasks, got %d", len(clone.Subtasks))
+	}
+	for _, subtask := range clone.Subtasks {
+		if subtask.Completed {
+			t.Errorf("Expected subtask %s to be made incomplete", subtask.Name)
+		}
+	}
+
+	db.First(trashed, "id = ?", trashed.ID)
+	if trashed.ParentID == nil || *trashed.ParentID != task.ID {
+		t.Errorf("Expected the trashed subtask to stay with the original, got parent %v", trashed.ParentID)
+	}
+
+	var events []models.TaskResetEvent
+	db.Where("task_id = ?", clone.ID).Find(&events)
+	if len(events) != 2 {
+		t.Errorf("Expected the copy's history to include the earlier reset, got %d events", len(events))
+	}
+	var remaining int64
+	db.Model(&models.TaskResetEvent{}).Where("task_id = ?", task.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("Expected no reset events left on the original, got %d", remaining)
+	}
+}
+
+func TestResetCompletedTasksSkipsPausedFrequency(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	frequency := &models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	if err := db.Create(frequency).Error; err != nil {
+		t.Fatalf("Failed to create frequency: %v", err)
+	}
+	db.Model(frequency).Update("enabled", false)
+
+	task := &models.Task{
+		Name:        "Vacation Task",
+		Completed:   true,
+		FrequencyID: &frequency.ID,
+		UpdatedAt:   time.Now().Add(-48 * time.Hour),
+	}
+	if err := db.Create(task).Error; err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	scheduler.resetCompletedTasks()
+
+	db.First(task, "id = ?", task.ID)
+	if !task.Completed {
+		t.Error("Expected task of a paused frequency to stay completed")
+	}
+
+	// Resuming lets the overdue reset happen
+	db.Model(frequency).Update("enabled", true)
+	scheduler.resetCompletedTasks()
+
+	db.First(task, "id = ?", task.ID)
+	if task.Completed {
+		t.Error("Expected task to be reset after resuming its frequency")
+	}
+}
+
+func TestRemindIncompleteTasks(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhook := newTestWebhookNotifier(server.URL)
+	scheduler.SetWebhookNotifier(webhook)
+
+	lead := 5
+	reminding := &models.Frequency{Name: "Every minute", Period: "* * * * *", ReminderLead: &lead}
+	silent := &models.Frequency{Name: "Also every minute", Period: "*/1 * * * *"}
+	db.Create(reminding)
+	db.Create(silent)
+
+	muted := models.Tag{Name: "Quiet", Color: "#000000"}
+	db.Create(&muted)
+	db.Model(&muted).Update("notifications_enabled", false)
+	muted.NotificationsEnabled = false
+
+	task := &models.Task{Name: "Stretch", FrequencyID: &reminding.ID}
+	done := &models.Task{Name: "Already done", Completed: true, FrequencyID: &reminding.ID}
+	quiet := &models.Task{Name: "Muted", FrequencyID: &reminding.ID, Tags: []models.Tag{muted}}
+	unreminded := &models.Task{Name: "No lead", FrequencyID: &silent.ID}
+	for _, tk := range []*models.Task{task, done, quiet, unreminded} {
+		if err := db.Create(tk).Error; err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	// A second check within the same period does not remind again
+	scheduler.remindIncompleteTasks()
+	scheduler.remindIncompleteTasks()
+	webhook.Stop()
+
+	_, payloads := receiver.snapshot()
+	if len(payloads) != 1 {
+		t.Fatalf("Expected 1 reminder payload, got %d", len(payloads))
+	}
+	if payloads[0].Event != "task_reminder" || payloads[0].TaskID != task.ID || !payloads[0].ResetAt.After(time.Now().Add(-time.Second)) {
+		t.Errorf("Unexpected reminder payload: %+v", payloads[0])
+	}
+
+	var reminded models.Task
+	db.First(&reminded, "id = ?", task.ID)
+	if reminded.RemindedAt == nil {
+		t.Error("Expected the reminder to be recorded")
+	}
+	if !reminded.UpdatedAt.Equal(task.UpdatedAt) {
+		t.Error("Expected recording the reminder to leave updated_at alone")
+	}
+}
+
+func TestResetCompletedTasksSkipNextReset(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	frequency := &models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	if err := db.Create(frequency).Error; err != nil {
+		t.Fatalf("Failed to create frequency: %v", err)
+	}
+
+	task := &models.Task{
+		Name:          "Done Early",
+		Completed:     true,
+		SkipNextReset: true,
+		FrequencyID:   &frequency.ID,
+		UpdatedAt:     time.Now().Add(-24 * time.Hour),
+	}
+	if err := db.Create(task).Error; err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	scheduler.resetCompletedTasks()
+
+	db.First(task, "id = ?", task.ID)
+	if !task.Completed {
+		t.Error("Expected the skipped reset to leave the task completed")
+	}
+	if task.SkipNextReset {
+		t.Error("Expected the skip flag to be cleared")
+	}
+
+	// The following reset happens as usual
+	db.Model(task).UpdateColumn("updated_at", time.Now().Add(-24*time.Hour))
+	scheduler.resetCompletedTasks()
+
+	db.First(task, "id = ?", task.ID)
+	if task.Completed {
+		t.Error("Expected the task to be reset once the skip was used")
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// webhookWorkers is the number of goroutines delivering webhook payloads.
+	webhookWorkers = 2
+	// webhookQueueSize is the number of payloads that can wait for delivery before new ones are dropped.
+	webhookQueueSize = 100
+	// webhookMaxAttempts is the number of delivery attempts made for each payload.
+	webhookMaxAttempts = 4
+	// webhookInitialBackoff is the delay before the first retry; it doubles with each attempt.
+	webhookInitialBackoff = time.Second
+	// webhookTimeout bounds how long a single delivery attempt may take.
+	webhookTimeout = 10 * time.Second
+)
+
+// TaskResetPayload represents the JSON body posted to the webhook when a task is reset,
+// or reminded of its upcoming reset. Event holds the matching WebSocket event type, and
+// ResetAt the time of the reset.
+type TaskResetPayload struct {
+	Event         string    `json:"event"`
+	TaskID        string    `json:"task_id"`
+	Name          string    `json:"name"`
+	FrequencyID   string    `json:"frequency_id"`
+	FrequencyName string    `json:"frequency"`
+	ResetAt       time.Time `json:"reset_at"`
+}
+
+// WebhookNotifier posts task reset payloads to an external URL in the background.
+// Deliveries are queued and handled by a fixed number of workers, retrying with
+// exponential backoff when the receiver responds with a server error.
+type WebhookNotifier struct {
+	url     string
+	client  *http.Client
+	queue   chan TaskResetPayload
+	backoff time.Duration
+	wg      sync.WaitGroup
+}
+
+// NewWebhookNotifier creates a webhook notifier for the given URL and starts its workers.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	n := &WebhookNotifier{
+		url:     url,
+		client:  &http.Client{Timeout: webhookTimeout},
+		queue:   make(chan TaskResetPayload, webhookQueueSize),
+		backoff: webhookInitialBackoff,
+	}
+
+	for i := 0; i < webhookWorkers; i++ {
+		n.wg.Add(1)
+		go n.work()
+	}
+	return n
+}
+
+// Notify queues a payload for delivery without blocking. The payload is dropped and
+// logged when the queue is full.
+func (n *WebhookNotifier) Notify(payload TaskResetPayload) {
+	select {
+	case n.queue <- payload:
+	default:
+		log.Printf("Webhook queue full, dropping %s notification for task %s", payload.Event, payload.TaskID)
+	}
+}
+
+// Stop stops accepting payloads and waits for queued deliveries to finish.
+func (n *WebhookNotifier) Stop() {
+	close(n.queue)
+	n.wg.Wait()
+}
+
+// work delivers queued payloads until the queue is closed.
+func (n *WebhookNotifier) work() {
+	defer n.wg.Done()
+	for payload := range n.queue {
+		if err := n.deliver(payload); err != nil {
+			log.Printf("Failed to deliver webhook for task %s: %v", payload.TaskID, err)
+		}
+	}
+}
+
+// deliver posts a payload to the webhook URL, retrying network errors and 5xx responses.
+func (n *WebhookNotifier) deliver(payload TaskResetPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := n.backoff
+	for attempt := 1; ; attempt++ {
+		err = n.post(body)
+		if err == nil || !retryable(err) || attempt == webhookMaxAttempts {
+			return err
+		}
+
+		log.Printf("Webhook delivery for task %s failed (attempt %d), retrying in %s: %v", payload.TaskID, attempt, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// webhookStatusError reports a non-2xx response from the webhook receiver.
+type webhookStatusError struct {
+	status int
+}
+
+// Error implements the error interface.
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.status)
+}
+
+// retryable reports whether a failed delivery should be attempted again. Client errors
+// are permanent; server errors and network failures are retried.
+func retryable(err error) bool {
+	if statusErr, ok := err.(*webhookStatusError); ok {
+		return statusErr.status >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// post makes a single delivery attempt.
+func (n *WebhookNotifier) post(body []byte) error {
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &webhookStatusError{status: resp.StatusCode}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jhoffmann/dailies/models"
+)
+
+// webhookReceiver records payloads posted to a test server, answering with the queued
+// status codes before falling back to 200 OK.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	attempts int
+	payloads []TaskResetPayload
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	wr.attempts++
+	if len(wr.statuses) > 0 {
+		status := wr.statuses[0]
+		wr.statuses = wr.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+
+	var payload TaskResetPayload
+	json.NewDecoder(r.Body).Decode(&payload)
+	wr.payloads = append(wr.payloads, payload)
+}
+
+func (wr *webhookReceiver) snapshot() (int, []TaskResetPayload) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return wr.attempts, append([]TaskResetPayload(nil), wr.payloads...)
+}
+
+func newTestWebhookNotifier(url string) *WebhookNotifier {
+	n := NewWebhookNotifier(url)
+	n.backoff = time.Millisecond
+	return n
+}
+
+func TestWebhookNotifierRetriesServerErrors(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	n := newTestWebhookNotifier(server.URL)
+	n.Notify(TaskResetPayload{TaskID: "task-1", Name: "Water plants"})
+	n.Stop()
+
+	attempts, payloads := receiver.snapshot()
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if len(payloads) != 1 || payloads[0].TaskID != "task-1" || payloads[0].Name != "Water plants" {
+		t.Errorf("Expected the payload to be delivered once, got %+v", payloads)
+	}
+}
+
+func TestWebhookNotifierDoesNotRetryClientErrors(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	n := newTestWebhookNotifier(server.URL)
+	n.Notify(TaskResetPayload{TaskID: "task-1"})
+	n.Stop()
+
+	if attempts, _ := receiver.snapshot(); attempts != 1 {
+		t.Errorf("Expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestResetCompletedTasksNotifiesWebhook(t *testing.T) {
+	scheduler, db := setupTestScheduler(t)
+
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhook := newTestWebhookNotifier(server.URL)
+	scheduler.SetWebhookNotifier(webhook)
+
+	frequency := &models.Frequency{Name: "Daily", Period: "0 0 * * *"}
+	db.Create(frequency)
+
+	muted := models.Tag{Name: "Quiet", Color: "#000000"}
+	db.Create(&muted)
+	db.Model(&muted).Update("notifications_enabled", false)
+	muted.NotificationsEnabled = false
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	task := &models.Task{Name: "Water plants", Completed: true, FrequencyID: &frequency.ID, UpdatedAt: yesterday}
+	quiet := &models.Task{Name: "Silent task", Completed: true, FrequencyID: &frequency.ID, UpdatedAt: yesterday, Tags: []models.Tag{muted}}
+	db.Create(task)
+	db.Create(quiet)
+
+	scheduler.resetCompletedTasks()
+	webhook.Stop()
+
+	_, payloads := receiver.snapshot()
+	if len(payloads) != 1 {
+		t.Fatalf("Expected 1 webhook payload, got %d", len(payloads))
+	}
+	if payloads[0].Event != "task_reset" || payloads[0].TaskID != task.ID || payloads[0].FrequencyName != "Daily" || payloads[0].ResetAt.IsZero() {
+		t.Errorf("Unexpected webhook payload: %+v", payloads[0])
+	}
+}
@@ -1,9 +1,13 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -13,16 +17,18 @@ import (
 type WebSocketEventType string
 
 const (
-	EventTaskReset  WebSocketEventType = "task_reset"
-	EventTaskUpdate WebSocketEventType = "task_update"
-	EventTaskCreate WebSocketEventType = "task_create"
-	EventTaskDelete WebSocketEventType = "task_delete"
-	EventTagUpdate  WebSocketEventType = "tag_update"
-	EventTagCreate  WebSocketEventType = "tag_create"
-	EventTagDelete  WebSocketEventType = "tag_delete"
-	EventFreqUpdate WebSocketEventType = "frequency_update"
-	EventFreqCreate WebSocketEventType = "frequency_create"
-	EventFreqDelete WebSocketEventType = "frequency_delete"
+	EventTaskReset    WebSocketEventType = "task_reset"
+	EventTaskReminder WebSocketEventType = "task_reminder"
+	EventTaskUpdate   WebSocketEventType = "task_update"
+	EventTaskCreate   WebSocketEventType = "task_create"
+	EventTaskDelete   WebSocketEventType = "task_delete"
+	EventTaskRefresh  WebSocketEventType = "task_list_refresh"
+	EventTagUpdate    WebSocketEventType = "tag_update"
+	EventTagCreate    WebSocketEventType = "tag_create"
+	EventTagDelete    WebSocketEventType = "tag_delete"
+	EventFreqUpdate   WebSocketEventType = "frequency_update"
+	EventFreqCreate   WebSocketEventType = "frequency_create"
+	EventFreqDelete   WebSocketEventType = "frequency_delete"
 )
 
 // WebSocketEvent represents a WebSocket event
@@ -31,6 +37,16 @@ type WebSocketEvent struct {
 	Data any                `json:"data"`
 }
 
+// SubscribeMessage represents a client message selecting the event types it receives.
+// An empty list restores the default of receiving every event.
+type SubscribeMessage struct {
+	Subscribe []WebSocketEventType `json:"subscribe"`
+}
+
+// broadcastBufferSize is the number of events that can wait to be broadcast before new
+// ones are dropped.
+const broadcastBufferSize = 64
+
 // WebSocketManager manages WebSocket connections and broadcasting
 type WebSocketManager struct {
 	clients    map[*websocket.Conn]bool
@@ -38,16 +54,81 @@ type WebSocketManager struct {
 	unregister chan *websocket.Conn
 	broadcast  chan WebSocketEvent
 	mutex      sync.RWMutex
+	// subscriptions holds the event types selected by clients that sent a subscription;
+	// other clients receive every event
+	subscriptions map[*websocket.Conn]map[WebSocketEventType]bool
+	// idleTimeout closes clients that send no messages for this long; zero disables it
+	idleTimeout time.Duration
+	// maxClients caps concurrent connections; zero means unlimited
+	maxClients int
+	// connections counts accepted connections, including ones not yet registered
+	connections int
 }
 
 // NewWebSocketManager creates a new WebSocket manager
 func NewWebSocketManager() *WebSocketManager {
 	return &WebSocketManager{
-		clients:    make(map[*websocket.Conn]bool),
-		register:   make(chan *websocket.Conn),
-		unregister: make(chan *websocket.Conn),
-		broadcast:  make(chan WebSocketEvent),
+		clients:       make(map[*websocket.Conn]bool),
+		subscriptions: make(map[*websocket.Conn]map[WebSocketEventType]bool),
+		register:      make(chan *websocket.Conn),
+		unregister:    make(chan *websocket.Conn),
+		broadcast:     make(chan WebSocketEvent, broadcastBufferSize),
+	}
+}
+
+// SetIdleTimeout sets how long a client may go without sending a message before it is
+// disconnected. Keepalive pings and pongs do not count as activity.
+func (manager *WebSocketManager) SetIdleTimeout(timeout time.Duration) {
+	manager.idleTimeout = timeout
+}
+
+// SetMaxClients sets the maximum number of concurrent WebSocket clients.
+// Zero allows an unlimited number of clients.
+func (manager *WebSocketManager) SetMaxClients(max int) {
+	manager.maxClients = max
+}
+
+// acquireConnection reserves a connection slot, reporting false when the limit is reached.
+func (manager *WebSocketManager) acquireConnection() bool {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	if manager.maxClients > 0 && manager.connections >= manager.maxClients {
+		return false
+	}
+	manager.connections++
+	return true
+}
+
+// releaseConnection frees a connection slot reserved by acquireConnection.
+func (manager *WebSocketManager) releaseConnection() {
+	manager.mutex.Lock()
+	manager.connections--
+	manager.mutex.Unlock()
+}
+
+// subscribe limits the events sent to a client to the given types, or restores every
+// event when types is empty.
+func (manager *WebSocketManager) subscribe(client *websocket.Conn, types []WebSocketEventType) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	if len(types) == 0 {
+		delete(manager.subscriptions, client)
+		return
+	}
+	selected := make(map[WebSocketEventType]bool, len(types))
+	for _, eventType := range types {
+		selected[eventType] = true
 	}
+	manager.subscriptions[client] = selected
+}
+
+// subscribed reports whether a client receives events of the given type.
+// The caller must hold the mutex.
+func (manager *WebSocketManager) subscribed(client *websocket.Conn, eventType WebSocketEventType) bool {
+	selected, ok := manager.subscriptions[client]
+	return !ok || selected[eventType]
 }
 
 // Run starts the WebSocket manager
@@ -66,28 +147,52 @@ func (manager *WebSocketManager) Run() {
 				delete(manager.clients, client)
 				client.Close()
 			}
+			delete(manager.subscriptions, client)
 			manager.mutex.Unlock()
 			log.Printf("WebSocket client disconnected. Total clients: %d", len(manager.clients))
 
 		case event := <-manager.broadcast:
-			manager.mutex.RLock()
+			manager.mutex.Lock()
 			for client := range manager.clients {
+				if !manager.subscribed(client, event.Type) {
+					continue
+				}
 				err := client.WriteJSON(event)
 				if err != nil {
 					log.Printf("WebSocket write error: %v", err)
 					client.Close()
 					delete(manager.clients, client)
+					delete(manager.subscriptions, client)
 				}
 			}
-			manager.mutex.RUnlock()
+			manager.mutex.Unlock()
 		}
 	}
 }
 
-// Broadcast sends an event to all connected clients
-func (manager *WebSocketManager) Broadcast(eventType WebSocketEventType, data any) {
+// ClientCount returns the number of connected WebSocket clients.
+func (manager *WebSocketManager) ClientCount() int {
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+	return len(manager.clients)
+}
+
+// Broadcast sends an event to all connected clients subscribed to its type. The event
+// type may be a WebSocketEventType or a plain string, as passed by the handlers.
+func (manager *WebSocketManager) Broadcast(eventType any, data any) {
+	var typ WebSocketEventType
+	switch t := eventType.(type) {
+	case WebSocketEventType:
+		typ = t
+	case string:
+		typ = WebSocketEventType(t)
+	default:
+		log.Printf("Invalid WebSocket event type %v, dropping message", eventType)
+		return
+	}
+
 	event := WebSocketEvent{
-		Type: eventType,
+		Type: typ,
 		Data: data,
 	}
 
@@ -108,28 +213,52 @@ var upgrader = websocket.Upgrader{
 // HandleWebSocket handles WebSocket connections
 func (manager *WebSocketManager) HandleWebSocket() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if !manager.acquireConnection() {
+			log.Printf("WebSocket connection rejected, limit of %d clients reached", manager.maxClients)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many WebSocket connections, try again later"})
+			return
+		}
+
 		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 		if err != nil {
 			log.Printf("WebSocket upgrade error: %v", err)
+			manager.releaseConnection()
 			return
 		}
 
 		manager.register <- conn
 
-		// Handle incoming messages (ping/pong, etc.)
+		// Handle incoming messages, such as subscriptions and keepalives
 		go func() {
 			defer func() {
 				manager.unregister <- conn
+				manager.releaseConnection()
 			}()
 
 			for {
-				_, _, err := conn.ReadMessage()
+				// Only data messages returned by ReadMessage extend the deadline
+				if manager.idleTimeout > 0 {
+					conn.SetReadDeadline(time.Now().Add(manager.idleTimeout))
+				}
+
+				_, message, err := conn.ReadMessage()
 				if err != nil {
-					if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+					var netErr net.Error
+					if errors.As(err, &netErr) && netErr.Timeout() {
+						log.Printf("WebSocket client idle for %s, closing connection", manager.idleTimeout)
+						message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "idle timeout")
+						conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+					} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 						log.Printf("WebSocket read error: %v", err)
 					}
 					break
 				}
+
+				// Other messages only count as activity
+				var subscription SubscribeMessage
+				if json.Unmarshal(message, &subscription) == nil && subscription.Subscribe != nil {
+					manager.subscribe(conn, subscription.Subscribe)
+				}
 			}
 		}()
 	}
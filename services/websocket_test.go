@@ -0,0 +1,192 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketIdleTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := NewWebSocketManager()
+	manager.SetIdleTimeout(100 * time.Millisecond)
+	go manager.Run()
+
+	r := gin.New()
+	r.GET("/ws", manager.HandleWebSocket())
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	// The client never sends anything, so the server should close it
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+
+	closeErr, ok := err.(*websocket.CloseError)
+	if !ok {
+		t.Fatalf("Expected a close frame, got %v", err)
+	}
+	if closeErr.Code != websocket.CloseNormalClosure {
+		t.Errorf("Expected close code %d, got %d", websocket.CloseNormalClosure, closeErr.Code)
+	}
+	if closeErr.Text != "idle timeout" {
+		t.Errorf("Expected close reason 'idle timeout', got '%s'", closeErr.Text)
+	}
+
+	// Unregistering happens just after the close frame is sent
+	var clients int
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		clients = manager.ClientCount()
+		if clients == 0 {
+			break
+		}
+	}
+	if clients != 0 {
+		t.Errorf("Expected idle client to be removed, %d clients remain", clients)
+	}
+}
+
+func TestWebSocketMaxClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := NewWebSocketManager()
+	manager.SetMaxClients(2)
+	go manager.Run()
+
+	r := gin.New()
+	r.GET("/ws", manager.HandleWebSocket())
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	for i := 0; i < 2; i++ {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("Expected connection %d to be accepted: %v", i+1, err)
+		}
+		defer conn.Close()
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("Expected the third connection to be rejected")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %v", http.StatusServiceUnavailable, resp)
+	}
+}
+
+func TestWebSocketSubscriptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := NewWebSocketManager()
+	go manager.Run()
+
+	r := gin.New()
+	r.GET("/ws", manager.HandleWebSocket())
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	all, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer all.Close()
+	subscriber, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer subscriber.Close()
+
+	if err := subscriber.WriteJSON(SubscribeMessage{Subscribe: []WebSocketEventType{EventTaskDelete}}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	// Wait until both clients are registered and the subscription is stored
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		manager.mutex.RLock()
+		ready := len(manager.clients) == 2 && len(manager.subscriptions) == 1
+		manager.mutex.RUnlock()
+		if ready {
+			break
+		}
+	}
+
+	// Handlers broadcast plain strings, the scheduler typed event names
+	manager.Broadcast("task_update", "first")
+	manager.Broadcast(EventTaskDelete, "second")
+
+	read := func(conn *websocket.Conn) WebSocketEvent {
+		var event WebSocketEvent
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		return event
+	}
+
+	if event := read(all); event.Type != EventTaskUpdate {
+		t.Errorf("Expected unsubscribed client to receive task_update first, got %s", event.Type)
+	}
+	if event := read(all); event.Type != EventTaskDelete {
+		t.Errorf("Expected unsubscribed client to receive task_delete, got %s", event.Type)
+	}
+	if event := read(subscriber); event.Type != EventTaskDelete {
+		t.Errorf("Expected subscriber to only receive task_delete, got %s", event.Type)
+	}
+}
+
+func TestWebSocketWriteErrorRemovesSubscription(t *testing.T) {
+	// Upgrade without the manager's handler, so no read loop unregisters the client
+	conns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Failed to upgrade: %v", err)
+			return
+		}
+		conns <- conn
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	conn := <-conns
+
+	manager := NewWebSocketManager()
+	manager.clients[conn] = true
+	manager.subscribe(conn, []WebSocketEventType{EventTaskUpdate})
+	conn.Close()
+	go manager.Run()
+
+	manager.Broadcast(EventTaskUpdate, "update")
+
+	var clients, subscriptions int
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		manager.mutex.RLock()
+		clients, subscriptions = len(manager.clients), len(manager.subscriptions)
+		manager.mutex.RUnlock()
+		if clients == 0 {
+			break
+		}
+	}
+	if clients != 0 || subscriptions != 0 {
+		t.Errorf("Expected the failed client to be removed, got %d clients and %d subscriptions", clients, subscriptions)
+	}
+}